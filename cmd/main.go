@@ -4,35 +4,57 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/pettersolberg88/kube-ops-view-ng/internal/k8s"
 	"github.com/pettersolberg88/kube-ops-view-ng/internal/server"
+	"k8s.io/apimachinery/pkg/api/resource"
 )
 
 func main() {
 	log.Println("Starting kube-ops-view-ng...")
 
-	// Initialize Kubernetes client
-	client, err := k8s.NewClient()
-	if err != nil {
-		log.Fatalf("Failed to create Kubernetes client: %v", err)
+	// Restrict watching to WATCH_NAMESPACES for tenants without cluster-wide RBAC
+	options := k8s.Options{
+		Namespaces:       splitList(os.Getenv("WATCH_NAMESPACES")),
+		SkipNodes:        os.Getenv("WATCH_NODES") == "false",
+		HistoryRetention: time.Hour,
+		HistoryMaxBytes:  32 << 20,
+	}
+
+	// History retention by duration and memory budget
+	if value := os.Getenv("HISTORY_RETENTION"); value != "" {
+		retention, err := time.ParseDuration(value)
+		if err != nil {
+			log.Fatalf("Invalid HISTORY_RETENTION: %v", err)
+		}
+		options.HistoryRetention = retention
+	}
+	if value := os.Getenv("HISTORY_MEMORY"); value != "" {
+		budget, err := resource.ParseQuantity(value)
+		if err != nil {
+			log.Fatalf("Invalid HISTORY_MEMORY: %v", err)
+		}
+		options.HistoryMaxBytes = budget.Value()
 	}
 
-	// Initialize Metrics client
-	metricsClient, err := k8s.NewMetricsClient()
+	// Custom node labels for topology fields, e.g. zone=example.com/rack
+	topologyLabels, err := k8s.ParseTopologyLabels(splitList(os.Getenv("NODE_TOPOLOGY_LABELS")))
 	if err != nil {
-		log.Printf("Failed to create Metrics client (metrics will be disabled): %v", err)
+		log.Fatalf("Invalid NODE_TOPOLOGY_LABELS: %v", err)
 	}
+	options.TopologyLabels = topologyLabels
 
-	// Start watcher
-	watcher := k8s.NewWatcher(client, metricsClient)
+	// Start one watcher per cluster, KUBE_CONTEXTS is a comma separated list of kubeconfig contexts
+	clusters := k8s.NewClusterSet(splitList(os.Getenv("KUBE_CONTEXTS")), options)
 	stopCh := make(chan struct{})
 	defer close(stopCh)
 
-	go watcher.Start(stopCh)
+	clusters.Start(stopCh)
 
 	// Start HTTP server
-	srv := server.NewServer(watcher)
+	srv := server.NewServer(clusters)
 	port := os.Getenv("PORT")
 	if port == "" {
 		port = "8080"
@@ -43,3 +65,14 @@ func main() {
 		log.Fatalf("Server failed: %v", err)
 	}
 }
+
+// splitList splits a comma separated list, ignoring empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,283 @@
+package k8s
+
+import (
+	"github.com/pettersolberg88/kube-ops-view-ng/internal/model"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// podAllocation is what a scheduled, non-terminal pod reserves on its node
+type podAllocation struct {
+	node     string
+	requests corev1.ResourceList
+	limits   corev1.ResourceList
+}
+
+// podRequirements returns the effective requests and limits of a pod the way the scheduler
+// computes them: app containers and native sidecars are summed, a regular init container
+// only needs what it uses next to the sidecars started before it, and the pod gets the
+// larger of both. Pod-level resources replace the container totals they set, and the
+// pod overhead is added on top, to limits only where a limit is set.
+func podRequirements(p *corev1.Pod) (requests, limits corev1.ResourceList) {
+	infeasible := resizeInfeasible(p)
+	requests, limits = corev1.ResourceList{}, corev1.ResourceList{}
+	for _, c := range p.Spec.Containers {
+		containerRequests, containerLimits := containerRequirements(c, findContainerStatus(p.Status.ContainerStatuses, c.Name), infeasible)
+		addResources(requests, containerRequests)
+		addResources(limits, containerLimits)
+	}
+
+	initRequests, initLimits := corev1.ResourceList{}, corev1.ResourceList{}
+	sidecarRequests, sidecarLimits := corev1.ResourceList{}, corev1.ResourceList{}
+	for _, c := range p.Spec.InitContainers {
+		containerRequests, containerLimits := containerRequirements(c, findContainerStatus(p.Status.InitContainerStatuses, c.Name), infeasible)
+		if c.RestartPolicy != nil && *c.RestartPolicy == corev1.ContainerRestartPolicyAlways {
+			addResources(requests, containerRequests)
+			addResources(limits, containerLimits)
+			addResources(sidecarRequests, containerRequests)
+			addResources(sidecarLimits, containerLimits)
+			containerRequests, containerLimits = sidecarRequests, sidecarLimits
+		} else {
+			regularRequests, regularLimits := corev1.ResourceList{}, corev1.ResourceList{}
+			addResources(regularRequests, containerRequests)
+			addResources(regularRequests, sidecarRequests)
+			addResources(regularLimits, containerLimits)
+			addResources(regularLimits, sidecarLimits)
+			containerRequests, containerLimits = regularRequests, regularLimits
+		}
+		maxResources(initRequests, containerRequests)
+		maxResources(initLimits, containerLimits)
+	}
+	maxResources(requests, initRequests)
+	maxResources(limits, initLimits)
+
+	if p.Spec.Resources != nil {
+		for name, q := range p.Spec.Resources.Requests {
+			requests[name] = q.DeepCopy()
+		}
+		for name, q := range p.Spec.Resources.Limits {
+			limits[name] = q.DeepCopy()
+		}
+	}
+
+	addResources(requests, p.Spec.Overhead)
+	for name, q := range p.Spec.Overhead {
+		if total, ok := limits[name]; ok {
+			total.Add(q)
+			limits[name] = total
+		}
+	}
+	return requests, limits
+}
+
+// containerRequirements returns the requests and limits of a container. While a resize is
+// pending the larger of the desired and the allocated or enacted resources is reserved, and
+// only the latter once the resize turned out infeasible.
+func containerRequirements(c corev1.Container, cs *corev1.ContainerStatus, infeasible bool) (requests, limits corev1.ResourceList) {
+	if cs == nil || cs.Resources == nil {
+		return c.Resources.Requests, c.Resources.Limits
+	}
+	requests, limits = corev1.ResourceList{}, corev1.ResourceList{}
+	if !infeasible {
+		maxResources(requests, c.Resources.Requests)
+		maxResources(limits, c.Resources.Limits)
+	}
+	maxResources(requests, cs.Resources.Requests)
+	maxResources(requests, cs.AllocatedResources)
+	maxResources(limits, cs.Resources.Limits)
+	return requests, limits
+}
+
+// resizeInfeasible reports whether the node rejected an in-place resize of the pod
+func resizeInfeasible(p *corev1.Pod) bool {
+	// Clusters before 1.33 report it in the deprecated resize status
+	if p.Status.Resize == corev1.PodResizeStatusInfeasible {
+		return true
+	}
+	for _, c := range p.Status.Conditions {
+		if c.Type == corev1.PodResizePending && c.Reason == corev1.PodReasonInfeasible {
+			return true
+		}
+	}
+	return false
+}
+
+// addResources adds the quantities of other to list
+func addResources(list, other corev1.ResourceList) {
+	for name, q := range other {
+		total := list[name]
+		total.Add(q)
+		list[name] = total
+	}
+}
+
+// maxResources raises the quantities of list to those of other where they are larger
+func maxResources(list, other corev1.ResourceList) {
+	for name, q := range other {
+		if total, ok := list[name]; !ok || q.Cmp(total) > 0 {
+			list[name] = q.DeepCopy()
+		}
+	}
+}
+
+// subtractResources subtracts the quantities of other from list, dropping those that reach zero
+func subtractResources(list, other corev1.ResourceList) {
+	for name, q := range other {
+		total := list[name]
+		total.Sub(q)
+		if total.IsZero() {
+			delete(list, name)
+		} else {
+			list[name] = total
+		}
+	}
+}
+
+// resourcesEqual reports whether two resource lists hold the same quantities
+func resourcesEqual(a, b corev1.ResourceList) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for name, q := range a {
+		other, ok := b[name]
+		if !ok || q.Cmp(other) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// isNodeResource reports whether a resource has a dedicated field in model.NodeAllocation
+func isNodeResource(name corev1.ResourceName) bool {
+	switch name {
+	case corev1.ResourceCPU, corev1.ResourceMemory, corev1.ResourceEphemeralStorage, corev1.ResourcePods:
+		return true
+	}
+	return false
+}
+
+// trackPodAllocation moves what a pod reserves between the totals of the nodes it leaves
+// and joins, and updates the allocation of those nodes. p is nil for a deleted pod. It
+// reports whether a node changed. w.mu must be held.
+func (w *Watcher) trackPodAllocation(key string, p *corev1.Pod) bool {
+	var allocation *podAllocation
+	if p != nil && p.Spec.NodeName != "" && p.Status.Phase != corev1.PodSucceeded && p.Status.Phase != corev1.PodFailed {
+		requests, limits := podRequirements(p)
+		allocation = &podAllocation{node: p.Spec.NodeName, requests: requests, limits: limits}
+	}
+
+	existing, exists := w.podAllocations[key]
+	if !exists && allocation == nil {
+		return false
+	}
+	if exists && allocation != nil && existing.node == allocation.node &&
+		resourcesEqual(existing.requests, allocation.requests) && resourcesEqual(existing.limits, allocation.limits) {
+		return false
+	}
+
+	if exists {
+		subtractResources(w.nodeRequests[existing.node], existing.requests)
+		subtractResources(w.nodeLimits[existing.node], existing.limits)
+		w.nodePods[existing.node]--
+		if w.nodePods[existing.node] == 0 {
+			delete(w.nodeRequests, existing.node)
+			delete(w.nodeLimits, existing.node)
+			delete(w.nodePods, existing.node)
+		}
+		delete(w.podAllocations, key)
+	}
+	if allocation != nil {
+		if w.nodePods[allocation.node] == 0 {
+			w.nodeRequests[allocation.node] = corev1.ResourceList{}
+			w.nodeLimits[allocation.node] = corev1.ResourceList{}
+		}
+		addResources(w.nodeRequests[allocation.node], allocation.requests)
+		addResources(w.nodeLimits[allocation.node], allocation.limits)
+		w.nodePods[allocation.node]++
+		w.podAllocations[key] = allocation
+	}
+
+	changed := false
+	if exists {
+		changed = w.refreshNodeAllocation(existing.node)
+	}
+	if allocation != nil && (!exists || existing.node != allocation.node) {
+		changed = w.refreshNodeAllocation(allocation.node) || changed
+	}
+	return changed
+}
+
+// nodeAllocation returns the allocation of a node from the totals of its pods and its usage
+// from metrics, which may be nil. w.mu must be held.
+func (w *Watcher) nodeAllocation(name string, podsAllocatable int64, metrics *model.Metrics) *model.NodeAllocation {
+	requests, limits := w.nodeRequests[name], w.nodeLimits[name]
+	allocation := &model.NodeAllocation{
+		Pods:                           w.nodePods[name],
+		PodsAllocatable:                podsAllocatable,
+		CPURequestedMilli:              requests.Cpu().MilliValue(),
+		CPULimitMilli:                  limits.Cpu().MilliValue(),
+		MemoryRequestedBytes:           requests.Memory().Value(),
+		MemoryLimitBytes:               limits.Memory().Value(),
+		EphemeralStorageRequestedBytes: requests.StorageEphemeral().Value(),
+		EphemeralStorageLimitBytes:     limits.StorageEphemeral().Value(),
+		Requests:                       resourceStrings(requests),
+		Limits:                         resourceStrings(limits),
+	}
+	for name, q := range requests {
+		if !isNodeResource(name) {
+			if allocation.ExtendedRequested == nil {
+				allocation.ExtendedRequested = make(map[string]int64)
+			}
+			allocation.ExtendedRequested[string(name)] = q.Value()
+		}
+	}
+	for name, q := range limits {
+		if !isNodeResource(name) {
+			if allocation.ExtendedLimits == nil {
+				allocation.ExtendedLimits = make(map[string]int64)
+			}
+			allocation.ExtendedLimits[string(name)] = q.Value()
+		}
+	}
+	setUsage(allocation, metrics)
+	return allocation
+}
+
+// refreshNodeAllocation updates the allocation of a cached node and reports whether it
+// changed. w.mu must be held.
+func (w *Watcher) refreshNodeAllocation(name string) bool {
+	existing, ok := w.nodes[name]
+	if !ok {
+		return false
+	}
+	var podsAllocatable int64
+	if existing.Allocation != nil {
+		podsAllocatable = existing.Allocation.PodsAllocatable
+	}
+	allocation := w.nodeAllocation(name, podsAllocatable, existing.Metrics)
+	if existing.Allocation != nil && allocation.Equals(*existing.Allocation) {
+		return false
+	}
+	node := copyNode(existing)
+	node.Allocation = allocation
+	w.nodes[name] = node
+	w.recordDelta(model.Delta{Type: model.DeltaNodeUpsert, Node: copyNode(node)})
+	return true
+}
+
+// withUsage returns a copy of an allocation with the usage from metrics, nil if allocation is nil
+func withUsage(allocation *model.NodeAllocation, metrics *model.Metrics) *model.NodeAllocation {
+	if allocation == nil {
+		return nil
+	}
+	updated := *allocation
+	setUsage(&updated, metrics)
+	return &updated
+}
+
+// setUsage sets the usage of an allocation from metrics, zero if metrics is nil
+func setUsage(allocation *model.NodeAllocation, metrics *model.Metrics) {
+	allocation.CPUUsageMilli, allocation.MemoryUsageBytes = 0, 0
+	if metrics != nil {
+		allocation.CPUUsageMilli, allocation.MemoryUsageBytes = metrics.CPUMilli, metrics.MemoryBytes
+	}
+}
@@ -1,64 +1,53 @@
 package k8s
 
 import (
-	"os"
-	"path/filepath"
-
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
-	"k8s.io/client-go/util/homedir"
 	"k8s.io/metrics/pkg/client/clientset/versioned"
 )
 
-// NewClient creates a new Kubernetes clientset
-func NewClient() (*kubernetes.Clientset, error) {
-	config, err := rest.InClusterConfig()
-	if err != nil {
-		// Fallback to local config
-		var kubeconfig string
-		if home := homedir.HomeDir(); home != "" {
-			kubeconfig = filepath.Join(home, ".kube", "config")
-		} else {
-			kubeconfig = os.Getenv("KUBECONFIG")
+// InClusterContext is the context name that selects the service account of the running pod
+const InClusterContext = "in-cluster"
+
+// NewConfig creates a rest config for the given kubeconfig context and returns the
+// resolved cluster name. An empty context uses the in-cluster config if available,
+// otherwise the current context of the kubeconfig.
+func NewConfig(context string) (*rest.Config, string, error) {
+	if context == "" || context == InClusterContext {
+		config, err := rest.InClusterConfig()
+		if err == nil || context == InClusterContext {
+			return config, InClusterContext, err
 		}
+	}
 
-		// Allow override via env var
-		if envKubeConfig := os.Getenv("KUBECONFIG"); envKubeConfig != "" {
-			kubeconfig = envKubeConfig
-		}
+	// Fallback to local config, KUBECONFIG may list several files
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	overrides := &clientcmd.ConfigOverrides{CurrentContext: context}
+	clientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, overrides)
 
-		config, err = clientcmd.BuildConfigFromFlags("", kubeconfig)
+	name := context
+	if name == "" {
+		raw, err := clientConfig.RawConfig()
 		if err != nil {
-			return nil, err
+			return nil, "", err
 		}
+		name = raw.CurrentContext
 	}
 
+	config, err := clientConfig.ClientConfig()
+	if err != nil {
+		return nil, name, err
+	}
+	return config, name, nil
+}
+
+// NewClient creates a new Kubernetes clientset
+func NewClient(config *rest.Config) (*kubernetes.Clientset, error) {
 	return kubernetes.NewForConfig(config)
 }
 
 // NewMetricsClient creates a new Kubernetes metrics clientset
-func NewMetricsClient() (*versioned.Clientset, error) {
-	config, err := rest.InClusterConfig()
-	if err != nil {
-		// Fallback to local config
-		var kubeconfig string
-		if home := homedir.HomeDir(); home != "" {
-			kubeconfig = filepath.Join(home, ".kube", "config")
-		} else {
-			kubeconfig = os.Getenv("KUBECONFIG")
-		}
-
-		// Allow override via env var
-		if envKubeConfig := os.Getenv("KUBECONFIG"); envKubeConfig != "" {
-			kubeconfig = envKubeConfig
-		}
-
-		config, err = clientcmd.BuildConfigFromFlags("", kubeconfig)
-		if err != nil {
-			return nil, err
-		}
-	}
-
+func NewMetricsClient(config *rest.Config) (*versioned.Clientset, error) {
 	return versioned.NewForConfig(config)
 }
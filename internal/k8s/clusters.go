@@ -0,0 +1,255 @@
+package k8s
+
+import (
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pettersolberg88/kube-ops-view-ng/internal/model"
+)
+
+// cluster is a configured cluster, err is set when no watcher could be created for it
+type cluster struct {
+	name    string
+	watcher *Watcher
+	err     error
+}
+
+// ClusterSet runs one Watcher per cluster and merges their state
+type ClusterSet struct {
+	clusters []*cluster
+
+	subscribersMu sync.RWMutex
+	subscribers   []chan model.ClusterState
+
+	counters counters // Deliveries of the merged state, the watchers count their own
+}
+
+// NewClusterSet creates a Watcher with the given options for every kubeconfig context.
+// An empty list watches the default cluster. Clusters that cannot be configured are kept
+// as degraded.
+func NewClusterSet(contexts []string, options Options) *ClusterSet {
+	if len(contexts) == 0 {
+		contexts = []string{""}
+	}
+
+	s := &ClusterSet{
+		subscribers: make([]chan model.ClusterState, 0),
+	}
+	for _, context := range contexts {
+		s.clusters = append(s.clusters, newCluster(context, options))
+	}
+	return s
+}
+
+func newCluster(context string, options Options) *cluster {
+	config, name, err := NewConfig(context)
+	if name == "" {
+		name = context
+	}
+	if err != nil {
+		log.Printf("[%s] Failed to load Kubernetes config (cluster will be degraded): %v", name, err)
+		return &cluster{name: name, err: err}
+	}
+
+	// Initialize Kubernetes client
+	client, err := NewClient(config)
+	if err != nil {
+		log.Printf("[%s] Failed to create Kubernetes client (cluster will be degraded): %v", name, err)
+		return &cluster{name: name, err: err}
+	}
+
+	// Initialize Metrics client
+	metricsClient, err := NewMetricsClient(config)
+	if err != nil {
+		log.Printf("[%s] Failed to create Metrics client (metrics will be disabled): %v", name, err)
+		metricsClient = nil
+	}
+
+	return &cluster{name: name, watcher: NewWatcher(name, client, metricsClient, options)}
+}
+
+// Start starts all watchers and forwards their updates to the merged subscribers
+func (s *ClusterSet) Start(stopCh <-chan struct{}) {
+	for _, c := range s.clusters {
+		if c.watcher == nil {
+			continue
+		}
+		go c.watcher.Start(stopCh)
+		go s.forward(c.watcher)
+	}
+}
+
+// forward sends the merged state to all subscribers whenever the watcher broadcasts
+func (s *ClusterSet) forward(w *Watcher) {
+	ch := w.subscribeForward()
+	defer w.Unsubscribe(ch)
+
+	for range ch {
+		state := s.GetSnapshot()
+		s.subscribersMu.RLock()
+		for _, sub := range s.subscribers {
+			select {
+			case sub <- state:
+				s.counters.countBroadcast(true)
+			default:
+				// Skip if channel is full
+				s.counters.countBroadcast(false)
+			}
+		}
+		s.subscribersMu.RUnlock()
+	}
+}
+
+// Watcher returns the watcher for the named cluster, or nil if it is unknown or degraded
+func (s *ClusterSet) Watcher(name string) *Watcher {
+	for _, c := range s.clusters {
+		if c.name == name {
+			return c.watcher
+		}
+	}
+	return nil
+}
+
+// Watchers returns all running watchers
+func (s *ClusterSet) Watchers() []*Watcher {
+	watchers := make([]*Watcher, 0, len(s.clusters))
+	for _, c := range s.clusters {
+		if c.watcher != nil {
+			watchers = append(watchers, c.watcher)
+		}
+	}
+	return watchers
+}
+
+// Has reports whether a cluster with the given name is configured
+func (s *ClusterSet) Has(name string) bool {
+	for _, c := range s.clusters {
+		if c.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Clusters returns the connection state of every configured cluster
+func (s *ClusterSet) Clusters() []model.ClusterInfo {
+	infos := make([]model.ClusterInfo, 0, len(s.clusters))
+	for _, c := range s.clusters {
+		if c.watcher == nil {
+			infos = append(infos, model.ClusterInfo{
+				Name:   c.name,
+				Status: model.ClusterDegraded,
+				Error:  c.err.Error(),
+			})
+			continue
+		}
+		infos = append(infos, c.watcher.Info())
+	}
+	return infos
+}
+
+// Subscribe returns a channel that receives merged cluster state updates
+func (s *ClusterSet) Subscribe() chan model.ClusterState {
+	s.subscribersMu.Lock()
+	defer s.subscribersMu.Unlock()
+
+	ch := make(chan model.ClusterState, 10)
+	s.subscribers = append(s.subscribers, ch)
+	return ch
+}
+
+// Unsubscribe removes a subscriber channel
+func (s *ClusterSet) Unsubscribe(ch chan model.ClusterState) {
+	s.subscribersMu.Lock()
+	defer s.subscribersMu.Unlock()
+
+	for i, sub := range s.subscribers {
+		if sub == ch {
+			close(ch)
+			s.subscribers = append(s.subscribers[:i], s.subscribers[i+1:]...)
+			break
+		}
+	}
+}
+
+// GetSnapshot returns the merged state of all clusters. The revision is the sum of
+// the cluster revisions, per-cluster revisions are listed in Clusters.
+func (s *ClusterSet) GetSnapshot() model.ClusterState {
+	state := newMergedState()
+	state.Clusters = s.Clusters()
+	for _, w := range s.Watchers() {
+		mergeState(&state, w.GetSnapshot())
+	}
+	return state
+}
+
+// newMergedState returns an empty state to merge cluster states into
+func newMergedState() model.ClusterState {
+	return model.ClusterState{
+		Nodes:     []model.Node{},
+		Pods:      []model.Pod{},
+		Workloads: []model.Workload{},
+		Rollouts:  []model.Rollout{},
+		Services:  []model.Service{},
+	}
+}
+
+// mergeState adds the objects of a cluster state to a merged state
+func mergeState(state *model.ClusterState, snapshot model.ClusterState) {
+	state.Revision += snapshot.Revision
+	state.Nodes = append(state.Nodes, snapshot.Nodes...)
+	state.Pods = append(state.Pods, snapshot.Pods...)
+	state.Workloads = append(state.Workloads, snapshot.Workloads...)
+	state.Rollouts = append(state.Rollouts, snapshot.Rollouts...)
+	state.Services = append(state.Services, snapshot.Services...)
+}
+
+// HistoryAt returns the merged state of all clusters at the given time, leaving out
+// clusters that have no history for it. It returns false if no cluster has.
+func (s *ClusterSet) HistoryAt(t time.Time) (model.ClusterState, bool) {
+	state := newMergedState()
+	found := false
+	for _, w := range s.Watchers() {
+		snapshot, ok := w.HistoryAt(t)
+		if !ok {
+			continue
+		}
+		found = true
+		mergeState(&state, snapshot)
+	}
+	return state, found
+}
+
+// HistoryRange returns the merged state of all clusters at from and the deltas of all
+// clusters recorded after it up to to, ordered by time
+func (s *ClusterSet) HistoryRange(from, to time.Time) (model.ClusterState, []model.Delta, bool) {
+	state := newMergedState()
+	deltas := []model.Delta{}
+	found := false
+	for _, w := range s.Watchers() {
+		snapshot, clusterDeltas, ok := w.HistoryRange(from, to)
+		if !ok {
+			continue
+		}
+		found = true
+		mergeState(&state, snapshot)
+		deltas = append(deltas, clusterDeltas...)
+	}
+	sort.SliceStable(deltas, func(i, j int) bool {
+		ti, _ := time.Parse(time.RFC3339Nano, deltas[i].Time)
+		tj, _ := time.Parse(time.RFC3339Nano, deltas[j].Time)
+		return ti.Before(tj)
+	})
+	return state, deltas, found
+}
+
+// Events returns the cached events of all clusters, newest first per cluster
+func (s *ClusterSet) Events(namespace, object string) []model.Event {
+	events := []model.Event{}
+	for _, w := range s.Watchers() {
+		events = append(events, w.Events(namespace, object)...)
+	}
+	return events
+}
@@ -0,0 +1,273 @@
+package k8s
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pettersolberg88/kube-ops-view-ng/internal/model"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// maxRecentEvents is the number of events kept per involved object
+const maxRecentEvents = 10
+
+// eventsDebounce is how long changed events are collected before the pods and nodes they
+// are about are updated, so that repeating events such as probe failures do not send an
+// upsert each time they are counted
+const eventsDebounce = 5 * time.Second
+
+// eventEntry is a cached event, name identifies it across updates
+type eventEntry struct {
+	name     string
+	lastSeen time.Time
+	event    model.Event
+}
+
+// eventObject identifies the pod or node an event is about
+type eventObject struct {
+	kind      string
+	namespace string
+	name      string
+}
+
+// eventKey returns the cache key of the object an event is about. Nodes are cluster-scoped,
+// so their namespace is ignored.
+func eventKey(kind, namespace, name string) string {
+	if kind == "Node" {
+		namespace = ""
+	}
+	return kind + "/" + namespace + "/" + name
+}
+
+func (w *Watcher) addEvent(obj interface{}) {
+	w.counters.countEvent("event", "add")
+	w.upsertEvent(obj.(*corev1.Event))
+}
+
+func (w *Watcher) updateEvent(old, new interface{}) {
+	w.counters.countEvent("event", "update")
+	w.upsertEvent(new.(*corev1.Event))
+}
+
+func (w *Watcher) deleteEvent(obj interface{}) {
+	w.counters.countEvent("event", "delete")
+	event, ok := obj.(*corev1.Event)
+	if !ok {
+		tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+		if !ok {
+			return
+		}
+		event, ok = tombstone.Obj.(*corev1.Event)
+		if !ok {
+			return
+		}
+	}
+
+	ref := event.InvolvedObject
+	if ref.Kind != "Pod" && ref.Kind != "Node" {
+		return
+	}
+	key := eventKey(ref.Kind, ref.Namespace, ref.Name)
+
+	w.mu.Lock()
+	entries := w.events[key]
+	for i, e := range entries {
+		if e.name == event.Namespace+"/"+event.Name {
+			entries = append(entries[:i:i], entries[i+1:]...)
+			break
+		}
+	}
+	if len(entries) == 0 {
+		delete(w.events, key)
+	} else {
+		w.events[key] = entries
+	}
+	w.markEvents(key, eventObject{kind: ref.Kind, namespace: ref.Namespace, name: ref.Name})
+	w.mu.Unlock()
+}
+
+// upsertEvent stores an event about a pod or node and keeps the latest maxRecentEvents per object
+func (w *Watcher) upsertEvent(event *corev1.Event) {
+	ref := event.InvolvedObject
+	if ref.Kind != "Pod" && ref.Kind != "Node" {
+		return
+	}
+	key := eventKey(ref.Kind, ref.Namespace, ref.Name)
+	entry := convertEvent(w.cluster, event)
+
+	w.mu.Lock()
+	entries := make([]eventEntry, 0, len(w.events[key])+1)
+	for _, e := range w.events[key] {
+		if e.name != entry.name {
+			entries = append(entries, e)
+		}
+	}
+	entries = append(entries, entry)
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].lastSeen.After(entries[j].lastSeen) })
+	if len(entries) > maxRecentEvents {
+		entries = entries[:maxRecentEvents]
+	}
+	w.events[key] = entries
+	w.markEvents(key, eventObject{kind: ref.Kind, namespace: ref.Namespace, name: ref.Name})
+	w.mu.Unlock()
+}
+
+// markEvents schedules the recent events of an object to be refreshed after
+// eventsDebounce. w.mu must be held.
+func (w *Watcher) markEvents(key string, object eventObject) {
+	w.staleEvents[key] = object
+	if w.eventsTimer == nil {
+		w.eventsTimer = time.AfterFunc(eventsDebounce, w.flushEvents)
+	}
+}
+
+// flushEvents refreshes the recent events of the objects marked since the last flush
+func (w *Watcher) flushEvents() {
+	w.mu.Lock()
+	toBroadcast := false
+	for key, object := range w.staleEvents {
+		if w.refreshEvents(object.kind, object.namespace, object.name) {
+			toBroadcast = true
+		}
+		delete(w.staleEvents, key)
+	}
+	w.eventsTimer = nil
+	w.mu.Unlock()
+	if toBroadcast {
+		w.broadcast()
+	}
+}
+
+// recentEvents returns the cached events of an object, newest first. w.mu must be held.
+func (w *Watcher) recentEvents(kind, namespace, name string) []model.Event {
+	entries := w.events[eventKey(kind, namespace, name)]
+	if len(entries) == 0 {
+		return nil
+	}
+	events := make([]model.Event, 0, len(entries))
+	for _, e := range entries {
+		events = append(events, e.event)
+	}
+	return events
+}
+
+// refreshEvents updates the recent events of a cached pod or node and reports whether
+// they changed. w.mu must be held.
+func (w *Watcher) refreshEvents(kind, namespace, name string) bool {
+	events := w.recentEvents(kind, namespace, name)
+	switch kind {
+	case "Pod":
+		key := podKey(namespace, name)
+		existing, ok := w.pods[key]
+		if !ok {
+			return false
+		}
+		pod := copyPod(existing)
+		pod.RecentEvents = events
+		if pod.Equals(existing) {
+			return false
+		}
+		w.pods[key] = pod
+		w.recordDelta(model.Delta{Type: model.DeltaPodUpsert, Pod: copyPod(pod)})
+		return true
+	case "Node":
+		existing, ok := w.nodes[name]
+		if !ok {
+			return false
+		}
+		node := copyNode(existing)
+		node.RecentEvents = events
+		if node.Equals(existing) {
+			return false
+		}
+		w.nodes[name] = node
+		w.recordDelta(model.Delta{Type: model.DeltaNodeUpsert, Node: copyNode(node)})
+		return true
+	}
+	return false
+}
+
+// Events returns the cached events, newest first. namespace and object narrow the result,
+// object is either a name or kind/name such as pod/web-0.
+func (w *Watcher) Events(namespace, object string) []model.Event {
+	kind, name, found := strings.Cut(object, "/")
+	if !found {
+		kind, name = "", object
+	}
+
+	w.mu.RLock()
+	var matches []eventEntry
+	for _, entries := range w.events {
+		for _, e := range entries {
+			if namespace != "" && e.event.Namespace != namespace {
+				continue
+			}
+			if kind != "" && !strings.EqualFold(e.event.Kind, kind) {
+				continue
+			}
+			if name != "" && e.event.Name != name {
+				continue
+			}
+			matches = append(matches, e)
+		}
+	}
+	w.mu.RUnlock()
+
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].lastSeen.After(matches[j].lastSeen) })
+	events := make([]model.Event, 0, len(matches))
+	for _, e := range matches {
+		events = append(events, e.event)
+	}
+	return events
+}
+
+// convertEvent converts a Kubernetes event, using the most precise timestamps available
+func convertEvent(cluster string, e *corev1.Event) eventEntry {
+	firstSeen := e.FirstTimestamp.Time
+	if firstSeen.IsZero() {
+		firstSeen = e.EventTime.Time
+	}
+	if firstSeen.IsZero() {
+		firstSeen = e.CreationTimestamp.Time
+	}
+	lastSeen := e.LastTimestamp.Time
+	if e.Series != nil && !e.Series.LastObservedTime.IsZero() {
+		lastSeen = e.Series.LastObservedTime.Time
+	}
+	if lastSeen.IsZero() {
+		lastSeen = firstSeen
+	}
+
+	count := int(e.Count)
+	if e.Series != nil && int(e.Series.Count) > count {
+		count = int(e.Series.Count)
+	}
+	if count == 0 {
+		count = 1
+	}
+
+	source := e.Source.Component
+	if source == "" {
+		source = e.ReportingController
+	}
+
+	return eventEntry{
+		name:     e.Namespace + "/" + e.Name,
+		lastSeen: lastSeen,
+		event: model.Event{
+			Cluster:   cluster,
+			Namespace: e.InvolvedObject.Namespace,
+			Kind:      e.InvolvedObject.Kind,
+			Name:      e.InvolvedObject.Name,
+			Type:      e.Type,
+			Reason:    e.Reason,
+			Message:   e.Message,
+			Source:    source,
+			Count:     count,
+			FirstSeen: firstSeen.Format(time.RFC3339),
+			LastSeen:  lastSeen.Format(time.RFC3339),
+		},
+	}
+}
@@ -0,0 +1,244 @@
+package k8s
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pettersolberg88/kube-ops-view-ng/internal/model"
+)
+
+// objects holds the cached objects a cluster state is built from
+type objects struct {
+	nodes     map[string]*model.Node
+	pods      map[string]*model.Pod      // keyed by podKey(namespace, name)
+	workloads map[string]*model.Workload // keyed by workloadKey(kind, namespace, name)
+	rollouts  map[string]*model.Rollout  // keyed by workloadKey(kind, namespace, name)
+	services  map[string]*model.Service  // keyed by serviceKey(namespace, name)
+}
+
+func newObjects() objects {
+	return objects{
+		nodes:     make(map[string]*model.Node),
+		pods:      make(map[string]*model.Pod),
+		workloads: make(map[string]*model.Workload),
+		rollouts:  make(map[string]*model.Rollout),
+		services:  make(map[string]*model.Service),
+	}
+}
+
+// clone returns a copy of the maps, the cached objects themselves are shared
+func (o objects) clone() objects {
+	c := objects{
+		nodes:     make(map[string]*model.Node, len(o.nodes)),
+		pods:      make(map[string]*model.Pod, len(o.pods)),
+		workloads: make(map[string]*model.Workload, len(o.workloads)),
+		rollouts:  make(map[string]*model.Rollout, len(o.rollouts)),
+		services:  make(map[string]*model.Service, len(o.services)),
+	}
+	for k, v := range o.nodes {
+		c.nodes[k] = v
+	}
+	for k, v := range o.pods {
+		c.pods[k] = v
+	}
+	for k, v := range o.workloads {
+		c.workloads[k] = v
+	}
+	for k, v := range o.rollouts {
+		c.rollouts[k] = v
+	}
+	for k, v := range o.services {
+		c.services[k] = v
+	}
+	return c
+}
+
+// historyEntry is a delta recorded in the history
+type historyEntry struct {
+	time  time.Time
+	delta model.Delta
+	size  int64 // Approximate memory use of the delta, see deltaSize
+}
+
+// history keeps a bounded log of deltas on top of a base state, so that the state at
+// any retained point in time can be rebuilt. Entries that fall out of the retention
+// window or memory budget are folded into the base state.
+type history struct {
+	mu        sync.Mutex
+	retention time.Duration
+	maxBytes  int64
+
+	// State before the first entry
+	baseTime     time.Time
+	baseRevision uint64
+	base         objects
+
+	entries []historyEntry
+	size    int64
+}
+
+func newHistory(retention time.Duration, maxBytes int64) *history {
+	return &history{
+		retention: retention,
+		maxBytes:  maxBytes,
+		baseTime:  time.Now(),
+		base:      newObjects(),
+	}
+}
+
+// add records a delta and evicts entries beyond the retention limits
+func (h *history) add(t time.Time, d model.Delta) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	size := deltaSize(d)
+	h.entries = append(h.entries, historyEntry{time: t, delta: d, size: size})
+	h.size += size
+	h.evict(t)
+}
+
+// evict folds the entries that are older than the retention at now or beyond the memory
+// budget into the base state. h.mu must be held.
+func (h *history) evict(now time.Time) {
+	for len(h.entries) > 0 {
+		oldest := h.entries[0]
+		if now.Sub(oldest.time) <= h.retention && (h.maxBytes <= 0 || h.size <= h.maxBytes) {
+			break
+		}
+		applyDelta(h.base, oldest.delta)
+		h.baseTime = oldest.time
+		h.baseRevision = oldest.delta.Revision
+		h.size -= oldest.size
+		h.entries[0] = historyEntry{}
+		h.entries = h.entries[1:]
+	}
+	// Without further changes the base state stays current, only how far back it goes
+	// is limited by the retention
+	if earliest := now.Add(-h.retention); h.baseTime.Before(earliest) {
+		h.baseTime = earliest
+	}
+}
+
+// replay rebuilds the state at t and returns the entries recorded after it up to until.
+// It returns false if t is older than the retained history.
+func (h *history) replay(t time.Time, until time.Time) (objects, uint64, []model.Delta, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.evict(time.Now())
+	if t.Before(h.baseTime) {
+		return objects{}, 0, nil, false
+	}
+
+	state := h.base.clone()
+
+	revision := h.baseRevision
+	var deltas []model.Delta
+	for _, e := range h.entries {
+		if e.time.After(t) {
+			if e.time.After(until) {
+				break
+			}
+			deltas = append(deltas, e.delta)
+			continue
+		}
+		applyDelta(state, e.delta)
+		revision = e.delta.Revision
+	}
+	return state, revision, deltas, true
+}
+
+// oldest returns the earliest time the state can be rebuilt for
+func (h *history) oldest() time.Time {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.evict(time.Now())
+	return h.baseTime
+}
+
+// applyDelta applies a delta to a state. Cached objects are shared with the history
+// entries, so they are copied rather than modified.
+func applyDelta(o objects, d model.Delta) {
+	nodes, pods := o.nodes, o.pods
+	switch d.Type {
+	case model.DeltaNodeUpsert:
+		nodes[d.Node.Name] = d.Node
+	case model.DeltaNodeDelete:
+		delete(nodes, d.Node.Name)
+	case model.DeltaPodUpsert:
+		pods[podKey(d.Pod.Namespace, d.Pod.Name)] = d.Pod
+	case model.DeltaPodDelete:
+		delete(pods, podKey(d.Pod.Namespace, d.Pod.Name))
+	case model.DeltaWorkloadUpsert:
+		o.workloads[workloadKey(d.Workload.Kind, d.Workload.Namespace, d.Workload.Name)] = d.Workload
+	case model.DeltaWorkloadDelete:
+		delete(o.workloads, workloadKey(d.Workload.Kind, d.Workload.Namespace, d.Workload.Name))
+	case model.DeltaRolloutUpsert:
+		o.rollouts[workloadKey(d.Rollout.Kind, d.Rollout.Namespace, d.Rollout.Name)] = d.Rollout
+	case model.DeltaRolloutDelete:
+		delete(o.rollouts, workloadKey(d.Rollout.Kind, d.Rollout.Namespace, d.Rollout.Name))
+	case model.DeltaServiceUpsert:
+		o.services[serviceKey(d.Service.Namespace, d.Service.Name)] = d.Service
+	case model.DeltaServiceDelete:
+		delete(o.services, serviceKey(d.Service.Namespace, d.Service.Name))
+	case model.DeltaMetrics:
+		for name, m := range d.Metrics.Nodes {
+			if node, ok := nodes[name]; ok {
+				node = copyNode(node)
+				node.Metrics = &m
+				node.Allocation = withUsage(node.Allocation, &m)
+				nodes[name] = node
+			}
+		}
+		for key, m := range d.Metrics.Pods {
+			if pod, ok := pods[key]; ok {
+				pod = copyPod(pod)
+				pod.Metrics = &m
+				pods[key] = pod
+			}
+		}
+		for key, containers := range d.Metrics.Containers {
+			if pod, ok := pods[key]; ok {
+				pod = copyPod(pod)
+				pod.Containers, _ = withContainerMetrics(pod.Containers, containers)
+				pod.InitContainers, _ = withContainerMetrics(pod.InitContainers, containers)
+				pods[key] = pod
+			}
+		}
+	}
+}
+
+// HistoryAt returns the cluster state at the given time. It returns false if history is
+// disabled or the time is older than the retained history.
+func (w *Watcher) HistoryAt(t time.Time) (model.ClusterState, bool) {
+	if w.history == nil {
+		return model.ClusterState{}, false
+	}
+	state, revision, _, ok := w.history.replay(t, t)
+	if !ok {
+		return model.ClusterState{}, false
+	}
+	return w.buildState(state, revision), true
+}
+
+// HistoryRange returns the cluster state at from and the deltas recorded after it up to
+// to. It returns false if history is disabled or from is older than the retained history.
+func (w *Watcher) HistoryRange(from, to time.Time) (model.ClusterState, []model.Delta, bool) {
+	if w.history == nil {
+		return model.ClusterState{}, nil, false
+	}
+	state, revision, deltas, ok := w.history.replay(from, to)
+	if !ok {
+		return model.ClusterState{}, nil, false
+	}
+	return w.buildState(state, revision), deltas, true
+}
+
+// HistoryOldest returns the earliest time history is retained for, zero if history is disabled
+func (w *Watcher) HistoryOldest() time.Time {
+	if w.history == nil {
+		return time.Time{}
+	}
+	return w.history.oldest()
+}
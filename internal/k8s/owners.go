@@ -0,0 +1,171 @@
+package k8s
+
+import (
+	"github.com/pettersolberg88/kube-ops-view-ng/internal/model"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// workloadRef identifies the controller of an intermediate owner such as a ReplicaSet
+type workloadRef struct {
+	kind string
+	name string
+}
+
+// workloadKey returns the cache key of a workload such as a ReplicaSet or Job
+func workloadKey(kind, namespace, name string) string {
+	return kind + "/" + namespace + "/" + name
+}
+
+// ownerHandlers returns the event handlers that track the controllers of ReplicaSets or
+// Jobs, so that pods can be attributed to the Deployment or CronJob above them. Events
+// are counted by the workload handlers of the same informer.
+func (w *Watcher) ownerHandlers(kind string) cache.ResourceEventHandlerFuncs {
+	return cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			w.setOwner(kind, obj, false)
+		},
+		UpdateFunc: func(old, new interface{}) {
+			w.setOwner(kind, new, false)
+		},
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			w.setOwner(kind, obj, true)
+		},
+	}
+}
+
+// setOwner records or forgets the controller of a ReplicaSet or Job and attributes its
+// pods to the new owner. Once it is forgotten the pods fall back to the ReplicaSet or Job.
+func (w *Watcher) setOwner(kind string, obj interface{}, deleted bool) {
+	o, err := meta.Accessor(obj)
+	if err != nil {
+		return
+	}
+	key := workloadKey(kind, o.GetNamespace(), o.GetName())
+	ref := metav1.GetControllerOfNoCopy(o)
+
+	w.mu.Lock()
+	existing, known := w.owners[key]
+	var owner workloadRef
+	if deleted || ref == nil {
+		if !known {
+			w.mu.Unlock()
+			return
+		}
+		delete(w.owners, key)
+		owner = workloadRef{kind: kind, name: o.GetName()}
+	} else {
+		owner = workloadRef{kind: ref.Kind, name: ref.Name}
+		if known && existing == owner {
+			w.mu.Unlock()
+			return
+		}
+		w.owners[key] = owner
+	}
+
+	toBroadcast := false
+	for k := range w.controllerPods[key] {
+		pod, ok := w.pods[k]
+		if !ok || (pod.WorkloadKind == owner.kind && pod.WorkloadName == owner.name) {
+			continue
+		}
+		updated := copyPod(pod)
+		updated.WorkloadKind = owner.kind
+		updated.WorkloadName = owner.name
+		updated.ControllerType = owner.kind
+		updated.CurrentRevision = w.isCurrentRevision(updated)
+		w.trackPodWorkload(k, pod, updated)
+		w.pods[k] = updated
+		w.markRollout(pod)
+		w.markRollout(updated)
+		w.recordDelta(model.Delta{Type: model.DeltaPodUpsert, Pod: copyPod(updated)})
+		toBroadcast = true
+	}
+	w.mu.Unlock()
+	if toBroadcast {
+		w.broadcast()
+	}
+}
+
+// trackPodWorkload maintains the index of pods by their workload. It is called when a pod
+// changes from old to new, either may be nil. w.mu must be held.
+func (w *Watcher) trackPodWorkload(key string, old, new *model.Pod) {
+	if old != nil && new != nil && old.Namespace == new.Namespace &&
+		old.WorkloadKind == new.WorkloadKind && old.WorkloadName == new.WorkloadName {
+		return
+	}
+	if old != nil && old.WorkloadKind != "" {
+		wk := workloadKey(old.WorkloadKind, old.Namespace, old.WorkloadName)
+		delete(w.workloadPods[wk], key)
+		if len(w.workloadPods[wk]) == 0 {
+			delete(w.workloadPods, wk)
+		}
+	}
+	if new != nil && new.WorkloadKind != "" {
+		wk := workloadKey(new.WorkloadKind, new.Namespace, new.WorkloadName)
+		if w.workloadPods[wk] == nil {
+			w.workloadPods[wk] = make(map[string]bool)
+		}
+		w.workloadPods[wk][key] = true
+	}
+}
+
+// trackPodController maintains the index of pods by their direct controller, the owner
+// resolveWorkload starts from. p is nil for a deleted pod. w.mu must be held.
+func (w *Watcher) trackPodController(key string, p *corev1.Pod) {
+	controller := ""
+	if p != nil {
+		if ref := directController(p); ref != nil {
+			controller = workloadKey(ref.Kind, p.Namespace, ref.Name)
+		}
+	}
+	old := w.podControllers[key]
+	if old == controller {
+		return
+	}
+	if old != "" {
+		delete(w.controllerPods[old], key)
+		if len(w.controllerPods[old]) == 0 {
+			delete(w.controllerPods, old)
+		}
+		delete(w.podControllers, key)
+	}
+	if controller != "" {
+		if w.controllerPods[controller] == nil {
+			w.controllerPods[controller] = make(map[string]bool)
+		}
+		w.controllerPods[controller][key] = true
+		w.podControllers[key] = controller
+	}
+}
+
+// directController returns the controller of a pod, or its first owner if none is marked
+// as controller
+func directController(p *corev1.Pod) *metav1.OwnerReference {
+	if ref := metav1.GetControllerOfNoCopy(p); ref != nil {
+		return ref
+	}
+	if len(p.OwnerReferences) == 0 {
+		return nil
+	}
+	return &p.OwnerReferences[0]
+}
+
+// resolveWorkload returns the top-level controller of a pod, following ReplicaSets and
+// Jobs to the Deployment, CronJob or other controller owning them. Until the owner is
+// known the direct controller is returned. w.mu must be held.
+func (w *Watcher) resolveWorkload(p *corev1.Pod) (string, string) {
+	ref := directController(p)
+	if ref == nil {
+		return "", ""
+	}
+	if owner, ok := w.owners[workloadKey(ref.Kind, p.Namespace, ref.Name)]; ok {
+		return owner.kind, owner.name
+	}
+	return ref.Kind, ref.Name
+}
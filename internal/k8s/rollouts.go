@@ -0,0 +1,246 @@
+package k8s
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/pettersolberg88/kube-ops-view-ng/internal/model"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// deploymentRevisionAnnotation numbers the ReplicaSets of a Deployment, the highest is the current one
+const deploymentRevisionAnnotation = "deployment.kubernetes.io/revision"
+
+// replicaSetRevision is a revision of a Deployment
+type replicaSetRevision struct {
+	revision int64
+	hash     string // pod-template-hash
+}
+
+// podRevision returns the revision a pod was created from
+func podRevision(p *corev1.Pod) string {
+	if hash := p.Labels[appsv1.DefaultDeploymentUniqueLabelKey]; hash != "" {
+		return hash
+	}
+	return p.Labels[appsv1.ControllerRevisionHashLabelKey]
+}
+
+// isRolloutKind reports whether rollouts of the workload kind are tracked
+func isRolloutKind(kind string) bool {
+	return kind == "Deployment" || kind == "StatefulSet"
+}
+
+// replicaSetRevisionHandlers returns the event handlers that track the current revision of
+// Deployments from their ReplicaSets. Events are counted by the workload handlers.
+func (w *Watcher) replicaSetRevisionHandlers() cache.ResourceEventHandlerFuncs {
+	return cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			w.setReplicaSetRevision(nil, obj)
+		},
+		UpdateFunc: func(old, new interface{}) {
+			w.setReplicaSetRevision(old, new)
+		},
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			w.setReplicaSetRevision(obj, nil)
+		},
+	}
+}
+
+// setReplicaSetRevision replaces the old version of a ReplicaSet with the new one, either
+// may be nil
+func (w *Watcher) setReplicaSetRevision(old, new interface{}) {
+	w.mu.Lock()
+	toBroadcast := false
+	if rs, ok := old.(*appsv1.ReplicaSet); ok {
+		if ref := metav1.GetControllerOfNoCopy(rs); ref != nil && ref.Kind == "Deployment" {
+			key := workloadKey(ref.Kind, rs.Namespace, ref.Name)
+			delete(w.replicaSets[key], rs.Name)
+			if len(w.replicaSets[key]) == 0 {
+				delete(w.replicaSets, key)
+			}
+		}
+	}
+	if rs, ok := new.(*appsv1.ReplicaSet); ok {
+		if ref := metav1.GetControllerOfNoCopy(rs); ref != nil && ref.Kind == "Deployment" {
+			key := workloadKey(ref.Kind, rs.Namespace, ref.Name)
+			if w.replicaSets[key] == nil {
+				w.replicaSets[key] = make(map[string]replicaSetRevision)
+			}
+			revision, _ := strconv.ParseInt(rs.Annotations[deploymentRevisionAnnotation], 10, 64)
+			w.replicaSets[key][rs.Name] = replicaSetRevision{revision: revision, hash: rs.Labels[appsv1.DefaultDeploymentUniqueLabelKey]}
+		}
+	}
+	for _, obj := range []interface{}{old, new} {
+		rs, ok := obj.(*appsv1.ReplicaSet)
+		if !ok {
+			continue
+		}
+		if ref := metav1.GetControllerOfNoCopy(rs); ref != nil && ref.Kind == "Deployment" {
+			current := replicaSetRevision{}
+			for _, r := range w.replicaSets[workloadKey(ref.Kind, rs.Namespace, ref.Name)] {
+				if r.revision >= current.revision {
+					current = r
+				}
+			}
+			toBroadcast = w.setCurrentRevision(ref.Kind, rs.Namespace, ref.Name, current.hash) || toBroadcast
+		}
+	}
+	w.mu.Unlock()
+	if toBroadcast {
+		w.broadcast()
+	}
+}
+
+// statefulSetRevisionHandlers returns the event handlers that track the current revision
+// of StatefulSets. Events are counted by the workload handlers.
+func (w *Watcher) statefulSetRevisionHandlers() cache.ResourceEventHandlerFuncs {
+	set := func(obj interface{}, deleted bool) {
+		if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+			obj = tombstone.Obj
+		}
+		s, ok := obj.(*appsv1.StatefulSet)
+		if !ok {
+			return
+		}
+		revision := s.Status.UpdateRevision
+		if deleted {
+			revision = ""
+		}
+		w.mu.Lock()
+		toBroadcast := w.setCurrentRevision("StatefulSet", s.Namespace, s.Name, revision)
+		w.mu.Unlock()
+		if toBroadcast {
+			w.broadcast()
+		}
+	}
+	return cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { set(obj, false) },
+		UpdateFunc: func(old, new interface{}) { set(new, false) },
+		DeleteFunc: func(obj interface{}) { set(obj, true) },
+	}
+}
+
+// setCurrentRevision records the current revision of a workload and flags its pods. It
+// reports whether anything changed. w.mu must be held.
+func (w *Watcher) setCurrentRevision(kind, namespace, name, revision string) bool {
+	key := workloadKey(kind, namespace, name)
+	if w.currentRevisions[key] == revision {
+		return false
+	}
+	if revision == "" {
+		delete(w.currentRevisions, key)
+	} else {
+		w.currentRevisions[key] = revision
+	}
+	w.staleRollouts[key] = true
+
+	for k := range w.workloadPods[key] {
+		pod, ok := w.pods[k]
+		if !ok {
+			continue
+		}
+		current := pod.WorkloadRevision != "" && pod.WorkloadRevision == revision
+		if current == pod.CurrentRevision {
+			continue
+		}
+		updated := copyPod(pod)
+		updated.CurrentRevision = current
+		w.pods[k] = updated
+		w.recordDelta(model.Delta{Type: model.DeltaPodUpsert, Pod: copyPod(updated)})
+	}
+	return true
+}
+
+// isCurrentRevision reports whether a pod runs the current revision of its workload. w.mu must be held.
+func (w *Watcher) isCurrentRevision(p *model.Pod) bool {
+	return p.WorkloadRevision != "" && w.currentRevisions[workloadKey(p.WorkloadKind, p.Namespace, p.WorkloadName)] == p.WorkloadRevision
+}
+
+// markRollout schedules the rollout of the workload of a pod for recomputation. w.mu must be held.
+func (w *Watcher) markRollout(p *model.Pod) {
+	if isRolloutKind(p.WorkloadKind) {
+		w.staleRollouts[workloadKey(p.WorkloadKind, p.Namespace, p.WorkloadName)] = true
+	}
+}
+
+// refreshRollouts recomputes the rollouts marked stale and records the changes. w.mu must be held.
+func (w *Watcher) refreshRollouts() {
+	if len(w.staleRollouts) == 0 {
+		return
+	}
+
+	rollouts := make(map[string]*model.Rollout, len(w.staleRollouts))
+	for key := range w.staleRollouts {
+		workload, ok := w.workloads[key]
+		if !ok || !isRolloutKind(workload.Kind) {
+			continue
+		}
+		rollouts[key] = &model.Rollout{
+			Cluster:      w.cluster,
+			Kind:         workload.Kind,
+			Namespace:    workload.Namespace,
+			Name:         workload.Name,
+			Revision:     w.currentRevisions[key],
+			Desired:      workload.Replicas,
+			StalledSince: stalledSince(workload),
+		}
+	}
+	for key, r := range rollouts {
+		for k := range w.workloadPods[key] {
+			pod, ok := w.pods[k]
+			if !ok || pod.Phase == string(corev1.PodSucceeded) || pod.Phase == string(corev1.PodFailed) {
+				continue
+			}
+			if pod.CurrentRevision {
+				r.Updated++
+				// Running pods are only healthy once ready
+				if pod.Health == model.HealthHealthy {
+					r.UpdatedReady++
+				}
+			} else {
+				r.OldRemaining++
+			}
+		}
+	}
+
+	now := time.Now().Format(time.RFC3339)
+	for key := range w.staleRollouts {
+		existing, exists := w.rollouts[key]
+		r, ok := rollouts[key]
+		if !ok {
+			if exists {
+				delete(w.rollouts, key)
+				w.recordDelta(model.Delta{Type: model.DeltaRolloutDelete, Rollout: existing})
+			}
+			continue
+		}
+		r.Complete = r.Updated == r.Desired && r.UpdatedReady == r.Desired && r.OldRemaining == 0
+		r.LastProgress = now
+		if exists && existing.Revision == r.Revision && existing.Updated == r.Updated &&
+			existing.UpdatedReady == r.UpdatedReady && existing.OldRemaining == r.OldRemaining {
+			r.LastProgress = existing.LastProgress
+		}
+		if exists && *existing == *r {
+			continue
+		}
+		w.rollouts[key] = r
+		w.recordDelta(model.Delta{Type: model.DeltaRolloutUpsert, Rollout: r})
+	}
+	w.staleRollouts = make(map[string]bool)
+}
+
+// stalledSince returns when a Deployment exceeded its progress deadline, empty if it has not
+func stalledSince(workload *model.Workload) string {
+	for _, c := range workload.Conditions {
+		if c.Type == string(appsv1.DeploymentProgressing) && c.Reason == "ProgressDeadlineExceeded" {
+			return c.LastTransitionTime
+		}
+	}
+	return ""
+}
@@ -0,0 +1,304 @@
+package k8s
+
+import (
+	"sort"
+
+	"github.com/pettersolberg88/kube-ops-view-ng/internal/model"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// endpoint is an endpoint of an EndpointSlice
+type endpoint struct {
+	id          string // podKey of the target pod, the target or hostname of others, empty if unknown
+	family      discoveryv1.AddressType
+	pod         bool
+	ready       bool
+	terminating bool
+}
+
+// serviceKey returns the cache key of a service
+func serviceKey(namespace, name string) string {
+	return namespace + "/" + name
+}
+
+func (w *Watcher) addService(obj interface{}) {
+	w.counters.countEvent("service", "add")
+	w.upsertService(obj.(*corev1.Service))
+}
+
+func (w *Watcher) updateService(old, new interface{}) {
+	w.counters.countEvent("service", "update")
+	w.upsertService(new.(*corev1.Service))
+}
+
+func (w *Watcher) deleteService(obj interface{}) {
+	w.counters.countEvent("service", "delete")
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	svc, ok := obj.(*corev1.Service)
+	if !ok {
+		return
+	}
+	key := serviceKey(svc.Namespace, svc.Name)
+	w.mu.Lock()
+	existing, ok := w.services[key]
+	if ok {
+		delete(w.services, key)
+		w.recordDelta(model.Delta{Type: model.DeltaServiceDelete, Service: existing})
+	}
+	w.mu.Unlock()
+	if ok {
+		w.broadcast()
+	}
+}
+
+// upsertService stores a service together with the state of its endpoints
+func (w *Watcher) upsertService(svc *corev1.Service) {
+	service := &model.Service{
+		Cluster:   w.cluster,
+		Namespace: svc.Namespace,
+		Name:      svc.Name,
+		Type:      string(svc.Spec.Type),
+		ClusterIP: svc.Spec.ClusterIP,
+		Selector:  svc.Spec.Selector,
+	}
+	key := serviceKey(svc.Namespace, svc.Name)
+
+	w.mu.Lock()
+	w.countEndpoints(service)
+	existing, exists := w.services[key]
+	toBroadcast := !exists || !service.Equals(existing)
+	if toBroadcast {
+		w.services[key] = service
+		w.recordDelta(model.Delta{Type: model.DeltaServiceUpsert, Service: service})
+	}
+	w.mu.Unlock()
+	if toBroadcast {
+		w.broadcast()
+	}
+}
+
+// countEndpoints sets the endpoint counts and severity of a service from its
+// EndpointSlices. An endpoint listed in several slices, as with dual-stack services, is
+// counted once. Endpoints that cannot be identified are counted for the address family
+// that lists the most of them. w.mu must be held.
+func (w *Watcher) countEndpoints(service *model.Service) {
+	var counts endpointCounts
+	anonymous := make(map[discoveryv1.AddressType]*endpointCounts)
+	seen := make(map[string]bool)
+	for _, endpoints := range w.endpointSlices[serviceKey(service.Namespace, service.Name)] {
+		for _, e := range endpoints {
+			if e.id == "" {
+				if anonymous[e.family] == nil {
+					anonymous[e.family] = &endpointCounts{}
+				}
+				anonymous[e.family].add(e)
+				continue
+			}
+			if seen[e.id] {
+				continue
+			}
+			seen[e.id] = true
+			counts.add(e)
+		}
+	}
+	var largest *endpointCounts
+	var largestFamily discoveryv1.AddressType
+	for family, c := range anonymous {
+		if largest == nil || c.total() > largest.total() || (c.total() == largest.total() && family < largestFamily) {
+			largest, largestFamily = c, family
+		}
+	}
+	if largest != nil {
+		counts.ready += largest.ready
+		counts.notReady += largest.notReady
+		counts.terminating += largest.terminating
+	}
+	service.Ready, service.NotReady, service.Terminating = counts.ready, counts.notReady, counts.terminating
+
+	service.Severity = model.SeverityOK
+	if len(service.Selector) > 0 && service.Ready == 0 {
+		service.Severity = model.SeverityCritical
+	} else if service.NotReady > 0 {
+		service.Severity = model.SeverityWarning
+	}
+}
+
+func (w *Watcher) addEndpointSlice(obj interface{}) {
+	w.counters.countEvent("endpointslice", "add")
+	w.setEndpointSlice(nil, obj)
+}
+
+func (w *Watcher) updateEndpointSlice(old, new interface{}) {
+	w.counters.countEvent("endpointslice", "update")
+	w.setEndpointSlice(old, new)
+}
+
+func (w *Watcher) deleteEndpointSlice(obj interface{}) {
+	w.counters.countEvent("endpointslice", "delete")
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	w.setEndpointSlice(obj, nil)
+}
+
+// setEndpointSlice replaces the old version of an EndpointSlice with the new one, either
+// may be nil, and updates the services and pods it lists
+func (w *Watcher) setEndpointSlice(old, new interface{}) {
+	services := make(map[string]bool)
+	pods := make(map[string]bool)
+
+	w.mu.Lock()
+	if slice, ok := old.(*discoveryv1.EndpointSlice); ok {
+		if name := slice.Labels[discoveryv1.LabelServiceName]; name != "" {
+			key := serviceKey(slice.Namespace, name)
+			for _, e := range w.endpointSlices[key][slice.Name] {
+				if e.pod {
+					delete(w.podEndpoints[e.id], slice.Name)
+					if len(w.podEndpoints[e.id]) == 0 {
+						delete(w.podEndpoints, e.id)
+					}
+					pods[e.id] = true
+				}
+			}
+			delete(w.endpointSlices[key], slice.Name)
+			if len(w.endpointSlices[key]) == 0 {
+				delete(w.endpointSlices, key)
+			}
+			services[key] = true
+		}
+	}
+	if slice, ok := new.(*discoveryv1.EndpointSlice); ok {
+		if name := slice.Labels[discoveryv1.LabelServiceName]; name != "" {
+			key := serviceKey(slice.Namespace, name)
+			endpoints := convertEndpoints(slice)
+			for _, e := range endpoints {
+				if e.pod {
+					if w.podEndpoints[e.id] == nil {
+						w.podEndpoints[e.id] = make(map[string]model.PodService)
+					}
+					w.podEndpoints[e.id][slice.Name] = model.PodService{Name: name, Ready: e.ready, Terminating: e.terminating}
+					pods[e.id] = true
+				}
+			}
+			if w.endpointSlices[key] == nil {
+				w.endpointSlices[key] = make(map[string][]endpoint)
+			}
+			w.endpointSlices[key][slice.Name] = endpoints
+			services[key] = true
+		}
+	}
+
+	toBroadcast := false
+	for key := range services {
+		existing, ok := w.services[key]
+		if !ok {
+			continue
+		}
+		service := copyService(existing)
+		w.countEndpoints(service)
+		if !service.Equals(existing) {
+			w.services[key] = service
+			w.recordDelta(model.Delta{Type: model.DeltaServiceUpsert, Service: service})
+			toBroadcast = true
+		}
+	}
+	for key := range pods {
+		existing, ok := w.pods[key]
+		if !ok {
+			continue
+		}
+		pod := copyPod(existing)
+		pod.Services = w.podServices(key)
+		if pod.Equals(existing) {
+			continue
+		}
+		w.pods[key] = pod
+		w.recordDelta(model.Delta{Type: model.DeltaPodUpsert, Pod: copyPod(pod)})
+		toBroadcast = true
+	}
+	w.mu.Unlock()
+	if toBroadcast {
+		w.broadcast()
+	}
+}
+
+// podServices returns the services a pod is an endpoint of, sorted by name. A pod listed
+// in several slices of a service is ready if any of them says so. w.mu must be held.
+func (w *Watcher) podServices(key string) []model.PodService {
+	entries := w.podEndpoints[key]
+	if len(entries) == 0 {
+		return nil
+	}
+	byName := make(map[string]model.PodService, len(entries))
+	for _, e := range entries {
+		if existing, ok := byName[e.Name]; ok {
+			e.Ready = e.Ready || existing.Ready
+			e.Terminating = e.Terminating && existing.Terminating
+		}
+		byName[e.Name] = e
+	}
+	services := make([]model.PodService, 0, len(byName))
+	for _, s := range byName {
+		services = append(services, s)
+	}
+	sort.Slice(services, func(i, j int) bool { return services[i].Name < services[j].Name })
+	return services
+}
+
+// endpointCounts counts endpoints by their state
+type endpointCounts struct {
+	ready, notReady, terminating int
+}
+
+func (c *endpointCounts) add(e endpoint) {
+	switch {
+	case e.terminating:
+		c.terminating++
+	case e.ready:
+		c.ready++
+	default:
+		c.notReady++
+	}
+}
+
+func (c *endpointCounts) total() int {
+	return c.ready + c.notReady + c.terminating
+}
+
+// convertEndpoints converts the endpoints of a slice. An unknown ready condition is
+// interpreted as ready, as the API recommends. Endpoints other than pods are identified by
+// their target or hostname, which are the same in the slices of each address family.
+func convertEndpoints(slice *discoveryv1.EndpointSlice) []endpoint {
+	endpoints := make([]endpoint, 0, len(slice.Endpoints))
+	for _, e := range slice.Endpoints {
+		ep := endpoint{
+			family:      slice.AddressType,
+			ready:       e.Conditions.Ready == nil || *e.Conditions.Ready,
+			terminating: e.Conditions.Terminating != nil && *e.Conditions.Terminating,
+		}
+		if e.TargetRef != nil && e.TargetRef.Kind == "Pod" {
+			namespace := e.TargetRef.Namespace
+			if namespace == "" {
+				namespace = slice.Namespace
+			}
+			ep.id = podKey(namespace, e.TargetRef.Name)
+			ep.pod = true
+		} else if e.TargetRef != nil {
+			ep.id = e.TargetRef.Kind + "/" + e.TargetRef.Namespace + "/" + e.TargetRef.Name
+		} else if e.Hostname != nil && *e.Hostname != "" {
+			ep.id = "hostname/" + *e.Hostname
+		}
+		endpoints = append(endpoints, ep)
+	}
+	return endpoints
+}
+
+// copyService returns a shallow copy of a cached service
+func copyService(s *model.Service) *model.Service {
+	c := *s
+	return &c
+}
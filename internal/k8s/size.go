@@ -0,0 +1,158 @@
+package k8s
+
+import (
+	"unsafe"
+
+	"github.com/pettersolberg88/kube-ops-view-ng/internal/model"
+)
+
+// mapEntryOverhead approximates the memory a map spends per entry besides the key and value
+const mapEntryOverhead = 16
+
+// deltaSize estimates the memory used by a delta from the sizes of its structs and the
+// lengths of its strings. It is called with w.mu held, so it walks the objects rather
+// than serializing them.
+func deltaSize(d model.Delta) int64 {
+	size := int64(unsafe.Sizeof(d)) + int64(len(d.Type)+len(d.Cluster)+len(d.Time))
+	if d.Pod != nil {
+		size += podSize(d.Pod)
+	}
+	if d.Node != nil {
+		size += nodeSize(d.Node)
+	}
+	if d.Workload != nil {
+		size += workloadSize(d.Workload)
+	}
+	if d.Rollout != nil {
+		r := d.Rollout
+		size += int64(unsafe.Sizeof(*r)) + stringsSize(r.Cluster, r.Kind, r.Namespace, r.Name, r.Revision, r.LastProgress, r.StalledSince)
+	}
+	if d.Service != nil {
+		s := d.Service
+		size += int64(unsafe.Sizeof(*s)) + stringsSize(s.Cluster, s.Namespace, s.Name, s.Type, s.ClusterIP, s.Severity) + labelsSize(s.Selector)
+	}
+	if d.Metrics != nil {
+		size += metricsDeltaSize(d.Metrics)
+	}
+	return size
+}
+
+func podSize(p *model.Pod) int64 {
+	size := int64(unsafe.Sizeof(*p)) + stringsSize(p.ID, p.Cluster, p.Name, p.Namespace, p.Status, p.Phase, p.Health,
+		p.NodeName, p.IP, p.StartTime, p.QOSClass, p.ControllerType, p.WorkloadKind, p.WorkloadName, p.WorkloadRevision)
+	size += labelsSize(p.Labels) + metricsSize(p.Metrics) + podResourcesSize(p.Resources) + eventsSize(p.RecentEvents)
+	for _, containers := range [][]model.ContainerInfo{p.Containers, p.InitContainers, p.EphemeralContainers} {
+		for i := range containers {
+			size += containerSize(&containers[i])
+		}
+	}
+	for _, s := range p.Services {
+		size += int64(unsafe.Sizeof(s)) + int64(len(s.Name))
+	}
+	for _, c := range p.VolumeClaims {
+		size += int64(unsafe.Sizeof(c)) + stringsSize(c.Volume, c.Name, c.StorageClass, c.Capacity, c.Phase) + stringsSize(c.AccessModes...)
+	}
+	return size
+}
+
+func containerSize(c *model.ContainerInfo) int64 {
+	size := int64(unsafe.Sizeof(*c)) + stringsSize(c.Name, c.State, c.Image, c.ImageID, c.ImagePullPolicy, c.Reason, c.Message)
+	if c.LastState != nil {
+		t := c.LastState
+		size += int64(unsafe.Sizeof(*t)) + stringsSize(t.Reason, t.Message, t.StartedAt, t.FinishedAt)
+	}
+	return size + metricsSize(c.Metrics) + podResourcesSize(c.Resources)
+}
+
+func nodeSize(n *model.Node) int64 {
+	size := int64(unsafe.Sizeof(*n)) + stringsSize(n.Cluster, n.Name, n.Status, n.Severity, n.Version, n.KernelVersion,
+		n.OSImage, n.ContainerRuntimeVersion, n.Zone, n.Region, n.InstanceType, n.Arch, n.OS, n.ProviderID, n.NodePool)
+	size += stringsSize(n.Roles...) + labelsSize(n.Labels) + labelsSize(n.Capacity) + labelsSize(n.Allocatable)
+	size += metricsSize(n.Metrics) + eventsSize(n.RecentEvents)
+	for _, c := range n.Conditions {
+		size += int64(unsafe.Sizeof(c)) + stringsSize(c.Type, c.Status, c.Reason, c.Message, c.LastTransitionTime)
+	}
+	for _, t := range n.Taints {
+		size += int64(unsafe.Sizeof(t)) + stringsSize(t.Key, t.Value, t.Effect, t.TimeAdded)
+	}
+	for _, v := range n.AttachedVolumes {
+		size += int64(unsafe.Sizeof(v)) + int64(len(v.Driver))
+	}
+	if a := n.Allocation; a != nil {
+		size += int64(unsafe.Sizeof(*a)) + labelsSize(a.Requests) + labelsSize(a.Limits)
+		for name := range a.ExtendedRequested {
+			size += mapEntryOverhead + int64(len(name)+8)
+		}
+		for name := range a.ExtendedLimits {
+			size += mapEntryOverhead + int64(len(name)+8)
+		}
+	}
+	return size
+}
+
+func workloadSize(wl *model.Workload) int64 {
+	size := int64(unsafe.Sizeof(*wl)) + stringsSize(wl.Cluster, wl.Kind, wl.Namespace, wl.Name, wl.Selector)
+	for _, c := range wl.Conditions {
+		size += int64(unsafe.Sizeof(c)) + stringsSize(c.Type, c.Status, c.Reason, c.Message, c.LastTransitionTime)
+	}
+	return size
+}
+
+func metricsDeltaSize(m *model.MetricsDelta) int64 {
+	metricsEntry := int64(mapEntryOverhead + unsafe.Sizeof(model.Metrics{}))
+	size := int64(unsafe.Sizeof(*m))
+	for name, v := range m.Nodes {
+		size += metricsEntry + stringsSize(name, v.CPU, v.Memory)
+	}
+	for name, v := range m.Pods {
+		size += metricsEntry + stringsSize(name, v.CPU, v.Memory)
+	}
+	for pod, containers := range m.Containers {
+		size += mapEntryOverhead + int64(len(pod))
+		for name, v := range containers {
+			size += metricsEntry + stringsSize(name, v.CPU, v.Memory)
+		}
+	}
+	return size
+}
+
+func metricsSize(m *model.Metrics) int64 {
+	if m == nil {
+		return 0
+	}
+	return int64(unsafe.Sizeof(*m)) + stringsSize(m.CPU, m.Memory)
+}
+
+func podResourcesSize(r *model.PodResources) int64 {
+	if r == nil {
+		return 0
+	}
+	return int64(unsafe.Sizeof(*r)) + stringsSize(r.CPURequested, r.CPULimit, r.MemoryRequested, r.MemoryLimit) +
+		labelsSize(r.Requests) + labelsSize(r.Limits)
+}
+
+func eventsSize(events []model.Event) int64 {
+	size := int64(0)
+	for _, e := range events {
+		size += int64(unsafe.Sizeof(e)) + stringsSize(e.Cluster, e.Namespace, e.Kind, e.Name, e.Type, e.Reason,
+			e.Message, e.Source, e.FirstSeen, e.LastSeen)
+	}
+	return size
+}
+
+// labelsSize estimates the memory used by a string map such as labels
+func labelsSize(m map[string]string) int64 {
+	size := int64(0)
+	for k, v := range m {
+		size += mapEntryOverhead + int64(2*unsafe.Sizeof(k)) + int64(len(k)+len(v))
+	}
+	return size
+}
+
+func stringsSize(values ...string) int64 {
+	size := int64(0)
+	for _, v := range values {
+		size += int64(len(v))
+	}
+	return size
+}
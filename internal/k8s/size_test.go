@@ -0,0 +1,89 @@
+package k8s
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pettersolberg88/kube-ops-view-ng/internal/model"
+)
+
+// fillValue sets every string in v to s and gives every pointer, slice and map one element
+func fillValue(v reflect.Value, s string) {
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		v.SetInt(1)
+	case reflect.Uint64:
+		v.SetUint(1)
+	case reflect.Bool:
+		v.SetBool(true)
+	case reflect.Pointer:
+		v.Set(reflect.New(v.Type().Elem()))
+		fillValue(v.Elem(), s)
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			fillValue(v.Field(i), s)
+		}
+	case reflect.Slice:
+		v.Set(reflect.MakeSlice(v.Type(), 1, 1))
+		fillValue(v.Index(0), s)
+	case reflect.Map:
+		key := reflect.New(v.Type().Key()).Elem()
+		fillValue(key, s)
+		value := reflect.New(v.Type().Elem()).Elem()
+		fillValue(value, s)
+		m := reflect.MakeMap(v.Type())
+		m.SetMapIndex(key, value)
+		v.Set(m)
+	}
+}
+
+// stringBytes returns the total length of the strings reachable from v
+func stringBytes(v reflect.Value) int64 {
+	switch v.Kind() {
+	case reflect.String:
+		return int64(v.Len())
+	case reflect.Pointer:
+		if v.IsNil() {
+			return 0
+		}
+		return stringBytes(v.Elem())
+	case reflect.Struct:
+		total := int64(0)
+		for i := 0; i < v.NumField(); i++ {
+			total += stringBytes(v.Field(i))
+		}
+		return total
+	case reflect.Slice:
+		total := int64(0)
+		for i := 0; i < v.Len(); i++ {
+			total += stringBytes(v.Index(i))
+		}
+		return total
+	case reflect.Map:
+		total := int64(0)
+		iter := v.MapRange()
+		for iter.Next() {
+			total += stringBytes(iter.Key()) + stringBytes(iter.Value())
+		}
+		return total
+	}
+	return 0
+}
+
+// TestDeltaSizeCoversAllFields fails when a string, slice or map is added to the model
+// without being counted by deltaSize. Every string of the fixture is longer than all
+// fixed overheads together, so a missing one cannot be made up by them.
+func TestDeltaSizeCoversAllFields(t *testing.T) {
+	const length = 1 << 16
+	var delta model.Delta
+	fillValue(reflect.ValueOf(&delta).Elem(), strings.Repeat("x", length))
+
+	want := stringBytes(reflect.ValueOf(delta))
+	size := deltaSize(delta)
+	if size < want || size >= want+length {
+		t.Errorf("deltaSize() = %d, want the %d bytes of strings plus overheads below %d, a field is not counted", size, want, length)
+	}
+}
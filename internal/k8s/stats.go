@@ -0,0 +1,91 @@
+package k8s
+
+import (
+	"sync"
+	"time"
+)
+
+// EventKey identifies an informer event by resource and event type (add, update, delete)
+type EventKey struct {
+	Resource string
+	Type     string
+}
+
+// Stats is a point in time copy of the telemetry counters of a Watcher
+type Stats struct {
+	BroadcastsSent      uint64
+	BroadcastsDropped   uint64
+	MetricsPolls        uint64
+	MetricsErrors       uint64
+	MetricsPollDuration time.Duration // Total time spent polling metrics-server
+	Events              map[EventKey]uint64
+}
+
+// counters holds the telemetry counters of a Watcher
+type counters struct {
+	mu                  sync.Mutex
+	broadcastsSent      uint64
+	broadcastsDropped   uint64
+	metricsPolls        uint64
+	metricsErrors       uint64
+	metricsPollDuration time.Duration
+	events              map[EventKey]uint64
+}
+
+func (c *counters) countBroadcast(sent bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if sent {
+		c.broadcastsSent++
+	} else {
+		c.broadcastsDropped++
+	}
+}
+
+func (c *counters) countMetricsPoll(duration time.Duration, failures int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.metricsPolls++
+	c.metricsErrors += uint64(failures)
+	c.metricsPollDuration += duration
+}
+
+func (c *counters) countEvent(resource, eventType string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.events == nil {
+		c.events = make(map[EventKey]uint64)
+	}
+	c.events[EventKey{Resource: resource, Type: eventType}]++
+}
+
+func (c *counters) snapshot() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	events := make(map[EventKey]uint64, len(c.events))
+	for k, v := range c.events {
+		events[k] = v
+	}
+	return Stats{
+		BroadcastsSent:      c.broadcastsSent,
+		BroadcastsDropped:   c.broadcastsDropped,
+		MetricsPolls:        c.metricsPolls,
+		MetricsErrors:       c.metricsErrors,
+		MetricsPollDuration: c.metricsPollDuration,
+		Events:              events,
+	}
+}
+
+// Stats returns the telemetry counters of the watcher
+func (w *Watcher) Stats() Stats {
+	return w.counters.snapshot()
+}
+
+// Stats returns the telemetry counters of the merged stream, only the broadcasts are counted
+func (s *ClusterSet) Stats() Stats {
+	return s.counters.snapshot()
+}
@@ -0,0 +1,162 @@
+package k8s
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pettersolberg88/kube-ops-view-ng/internal/model"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// nodeLostReason is set on pods of unreachable nodes by the node lifecycle controller
+const nodeLostReason = "NodeLost"
+
+// failedReasons are display reasons of containers that cannot run without intervention
+var failedReasons = map[string]bool{
+	"CrashLoopBackOff":           true,
+	"ImagePullBackOff":           true,
+	"ErrImagePull":               true,
+	"ErrImageNeverPull":          true,
+	"InvalidImageName":           true,
+	"CreateContainerConfigError": true,
+	"CreateContainerError":       true,
+	"RunContainerError":          true,
+	"PreStartHookError":          true,
+	"PostStartHookError":         true,
+	"OOMKilled":                  true,
+	"Error":                      true,
+	"ContainerCannotRun":         true,
+	"DeadlineExceeded":           true,
+	"Evicted":                    true,
+}
+
+// progressingReasons are display reasons of pods that are expected to settle on their own
+var progressingReasons = map[string]bool{
+	"Pending":           true,
+	"ContainerCreating": true,
+	"PodInitializing":   true,
+	"SchedulingGated":   true,
+	"Terminating":       true,
+}
+
+// podReason returns the status shown by kubectl get pods, e.g. Running, Completed,
+// CrashLoopBackOff, Init:0/2, Evicted or Terminating
+func podReason(p *corev1.Pod) string {
+	reason := string(p.Status.Phase)
+	if p.Status.Reason != "" {
+		reason = p.Status.Reason
+	}
+	for _, condition := range p.Status.Conditions {
+		if condition.Type == corev1.PodScheduled && condition.Reason == corev1.PodReasonSchedulingGated {
+			reason = corev1.PodReasonSchedulingGated
+		}
+	}
+
+	sidecars := make(map[string]bool)
+	for _, c := range p.Spec.InitContainers {
+		if c.RestartPolicy != nil && *c.RestartPolicy == corev1.ContainerRestartPolicyAlways {
+			sidecars[c.Name] = true
+		}
+	}
+
+	initializing := false
+	for i, cs := range p.Status.InitContainerStatuses {
+		switch {
+		case cs.State.Terminated != nil && cs.State.Terminated.ExitCode == 0:
+			continue
+		case sidecars[cs.Name] && cs.Started != nil && *cs.Started:
+			continue
+		case cs.State.Terminated != nil:
+			reason = "Init:" + terminatedReason(cs.State.Terminated)
+		case cs.State.Waiting != nil && cs.State.Waiting.Reason != "" && cs.State.Waiting.Reason != "PodInitializing":
+			reason = "Init:" + cs.State.Waiting.Reason
+		default:
+			reason = fmt.Sprintf("Init:%d/%d", i, len(p.Spec.InitContainers))
+		}
+		initializing = true
+		break
+	}
+
+	if !initializing || podConditionTrue(p, corev1.PodInitialized) {
+		running := false
+		for i := len(p.Status.ContainerStatuses) - 1; i >= 0; i-- {
+			cs := p.Status.ContainerStatuses[i]
+			if cs.State.Waiting != nil && cs.State.Waiting.Reason != "" {
+				reason = cs.State.Waiting.Reason
+			} else if cs.State.Terminated != nil {
+				reason = terminatedReason(cs.State.Terminated)
+			} else if cs.Ready && cs.State.Running != nil {
+				running = true
+			}
+		}
+		// A pod with a completed container is still running if another one is
+		if reason == "Completed" && running {
+			if podConditionTrue(p, corev1.PodReady) {
+				reason = "Running"
+			} else {
+				reason = "NotReady"
+			}
+		}
+	}
+
+	if p.DeletionTimestamp != nil && p.Status.Reason == nodeLostReason {
+		reason = "Unknown"
+	} else if p.DeletionTimestamp != nil && p.Status.Phase != corev1.PodSucceeded && p.Status.Phase != corev1.PodFailed {
+		reason = "Terminating"
+	}
+	return reason
+}
+
+// terminatedReason returns the reason of a terminated container, falling back to the
+// signal or exit code
+func terminatedReason(t *corev1.ContainerStateTerminated) string {
+	if t.Reason != "" {
+		return t.Reason
+	}
+	if t.Signal != 0 {
+		return fmt.Sprintf("Signal:%d", t.Signal)
+	}
+	return fmt.Sprintf("ExitCode:%d", t.ExitCode)
+}
+
+// podHealth categorises a pod by its phase and display reason
+func podHealth(p *corev1.Pod, reason string) string {
+	if p.Status.Phase == corev1.PodFailed {
+		return model.HealthFailed
+	}
+	if p.Status.Phase == corev1.PodSucceeded {
+		return model.HealthHealthy
+	}
+
+	name := strings.TrimPrefix(reason, "Init:")
+	if failedReasons[name] || strings.HasPrefix(name, "ExitCode:") || strings.HasPrefix(name, "Signal:") {
+		return model.HealthFailed
+	}
+	if progressingReasons[reason] || strings.HasPrefix(reason, "Init:") {
+		return model.HealthProgressing
+	}
+	if reason != string(corev1.PodRunning) {
+		return model.HealthWarning
+	}
+
+	if podConditionTrue(p, corev1.PodReady) {
+		return model.HealthHealthy
+	}
+	// Containers that have not passed their startup probe yet are still starting
+	for _, cs := range p.Status.ContainerStatuses {
+		if cs.RestartCount == 0 && (cs.Started == nil || !*cs.Started) {
+			return model.HealthProgressing
+		}
+	}
+	return model.HealthWarning
+}
+
+// podConditionTrue reports whether the pod condition of the given type is true
+func podConditionTrue(p *corev1.Pod, conditionType corev1.PodConditionType) bool {
+	for _, condition := range p.Status.Conditions {
+		if condition.Type == conditionType {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
@@ -0,0 +1,213 @@
+package k8s
+
+import (
+	"testing"
+
+	"github.com/pettersolberg88/kube-ops-view-ng/internal/model"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func runningStatus(name string, ready bool) corev1.ContainerStatus {
+	started := true
+	return corev1.ContainerStatus{Name: name, Ready: ready, Started: &started, State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}}
+}
+
+func waitingStatus(name, reason string) corev1.ContainerStatus {
+	return corev1.ContainerStatus{Name: name, State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: reason}}}
+}
+
+func terminatedStatus(name, reason string, exitCode, signal int32) corev1.ContainerStatus {
+	return corev1.ContainerStatus{Name: name, State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: reason, ExitCode: exitCode, Signal: signal}}}
+}
+
+func podCondition(conditionType corev1.PodConditionType, status corev1.ConditionStatus, reason string) corev1.PodCondition {
+	return corev1.PodCondition{Type: conditionType, Status: status, Reason: reason}
+}
+
+func TestPodReasonAndHealth(t *testing.T) {
+	always := corev1.ContainerRestartPolicyAlways
+	deleted := metav1.Now()
+	twoInits := []corev1.Container{{Name: "init-a"}, {Name: "init-b"}}
+
+	tests := []struct {
+		name   string
+		pod    corev1.Pod
+		reason string
+		health string
+	}{
+		{
+			name: "running and ready",
+			pod: corev1.Pod{Status: corev1.PodStatus{
+				Phase:             corev1.PodRunning,
+				Conditions:        []corev1.PodCondition{podCondition(corev1.PodReady, corev1.ConditionTrue, "")},
+				ContainerStatuses: []corev1.ContainerStatus{runningStatus("app", true)},
+			}},
+			reason: "Running",
+			health: model.HealthHealthy,
+		},
+		{
+			name: "running before startup probe passed",
+			pod: corev1.Pod{Status: corev1.PodStatus{
+				Phase:             corev1.PodRunning,
+				ContainerStatuses: []corev1.ContainerStatus{{Name: "app", State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}}},
+			}},
+			reason: "Running",
+			health: model.HealthProgressing,
+		},
+		{
+			name: "completed",
+			pod: corev1.Pod{Status: corev1.PodStatus{
+				Phase:             corev1.PodSucceeded,
+				ContainerStatuses: []corev1.ContainerStatus{terminatedStatus("app", "Completed", 0, 0)},
+			}},
+			reason: "Completed",
+			health: model.HealthHealthy,
+		},
+		{
+			name: "completed container next to a running ready one",
+			pod: corev1.Pod{Status: corev1.PodStatus{
+				Phase:             corev1.PodRunning,
+				Conditions:        []corev1.PodCondition{podCondition(corev1.PodReady, corev1.ConditionTrue, "")},
+				ContainerStatuses: []corev1.ContainerStatus{terminatedStatus("job", "Completed", 0, 0), runningStatus("app", true)},
+			}},
+			reason: "Running",
+			health: model.HealthHealthy,
+		},
+		{
+			name: "completed container next to a running pod that is not ready",
+			pod: corev1.Pod{Status: corev1.PodStatus{
+				Phase:             corev1.PodRunning,
+				Conditions:        []corev1.PodCondition{podCondition(corev1.PodReady, corev1.ConditionFalse, "")},
+				ContainerStatuses: []corev1.ContainerStatus{terminatedStatus("job", "Completed", 0, 0), runningStatus("app", true)},
+			}},
+			reason: "NotReady",
+			health: model.HealthWarning,
+		},
+		{
+			name: "evicted",
+			pod: corev1.Pod{Status: corev1.PodStatus{
+				Phase:  corev1.PodFailed,
+				Reason: "Evicted",
+			}},
+			reason: "Evicted",
+			health: model.HealthFailed,
+		},
+		{
+			name: "node lost while deleting",
+			pod: corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{DeletionTimestamp: &deleted},
+				Status: corev1.PodStatus{
+					Phase:             corev1.PodRunning,
+					Reason:            nodeLostReason,
+					ContainerStatuses: []corev1.ContainerStatus{runningStatus("app", true)},
+				},
+			},
+			reason: "Unknown",
+			health: model.HealthWarning,
+		},
+		{
+			name: "terminating",
+			pod: corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{DeletionTimestamp: &deleted},
+				Status: corev1.PodStatus{
+					Phase:             corev1.PodRunning,
+					ContainerStatuses: []corev1.ContainerStatus{runningStatus("app", true)},
+				},
+			},
+			reason: "Terminating",
+			health: model.HealthProgressing,
+		},
+		{
+			name: "first of two init containers running",
+			pod: corev1.Pod{
+				Spec: corev1.PodSpec{InitContainers: twoInits},
+				Status: corev1.PodStatus{
+					Phase:                 corev1.PodPending,
+					InitContainerStatuses: []corev1.ContainerStatus{runningStatus("init-a", false), waitingStatus("init-b", "PodInitializing")},
+					ContainerStatuses:     []corev1.ContainerStatus{waitingStatus("app", "PodInitializing")},
+				},
+			},
+			reason: "Init:0/2",
+			health: model.HealthProgressing,
+		},
+		{
+			name: "init container crash looping",
+			pod: corev1.Pod{
+				Spec: corev1.PodSpec{InitContainers: twoInits},
+				Status: corev1.PodStatus{
+					Phase:                 corev1.PodPending,
+					InitContainerStatuses: []corev1.ContainerStatus{waitingStatus("init-a", "CrashLoopBackOff"), waitingStatus("init-b", "PodInitializing")},
+				},
+			},
+			reason: "Init:CrashLoopBackOff",
+			health: model.HealthFailed,
+		},
+		{
+			name: "init container failed with an exit code",
+			pod: corev1.Pod{
+				Spec: corev1.PodSpec{InitContainers: twoInits},
+				Status: corev1.PodStatus{
+					Phase:                 corev1.PodPending,
+					InitContainerStatuses: []corev1.ContainerStatus{terminatedStatus("init-a", "", 1, 0), waitingStatus("init-b", "PodInitializing")},
+				},
+			},
+			reason: "Init:ExitCode:1",
+			health: model.HealthFailed,
+		},
+		{
+			name: "init container killed by a signal",
+			pod: corev1.Pod{
+				Spec: corev1.PodSpec{InitContainers: twoInits},
+				Status: corev1.PodStatus{
+					Phase:                 corev1.PodPending,
+					InitContainerStatuses: []corev1.ContainerStatus{terminatedStatus("init-a", "", 137, 9), waitingStatus("init-b", "PodInitializing")},
+				},
+			},
+			reason: "Init:Signal:9",
+			health: model.HealthFailed,
+		},
+		{
+			name: "started native sidecar is skipped",
+			pod: corev1.Pod{
+				Spec: corev1.PodSpec{InitContainers: []corev1.Container{{Name: "proxy", RestartPolicy: &always}, {Name: "migrate"}}},
+				Status: corev1.PodStatus{
+					Phase:                 corev1.PodPending,
+					InitContainerStatuses: []corev1.ContainerStatus{runningStatus("proxy", true), runningStatus("migrate", false)},
+				},
+			},
+			reason: "Init:1/2",
+			health: model.HealthProgressing,
+		},
+		{
+			name: "scheduling gated",
+			pod: corev1.Pod{Status: corev1.PodStatus{
+				Phase:      corev1.PodPending,
+				Conditions: []corev1.PodCondition{podCondition(corev1.PodScheduled, corev1.ConditionFalse, corev1.PodReasonSchedulingGated)},
+			}},
+			reason: "SchedulingGated",
+			health: model.HealthProgressing,
+		},
+		{
+			name: "exit code without a reason",
+			pod: corev1.Pod{Status: corev1.PodStatus{
+				Phase:             corev1.PodRunning,
+				ContainerStatuses: []corev1.ContainerStatus{terminatedStatus("app", "", 1, 0)},
+			}},
+			reason: "ExitCode:1",
+			health: model.HealthFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reason := podReason(&tt.pod)
+			if reason != tt.reason {
+				t.Errorf("podReason() = %q, want %q", reason, tt.reason)
+			}
+			if health := podHealth(&tt.pod, reason); health != tt.health {
+				t.Errorf("podHealth() = %q, want %q", health, tt.health)
+			}
+		})
+	}
+}
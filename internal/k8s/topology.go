@@ -0,0 +1,82 @@
+package k8s
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// topologyLabels are the well-known node labels of each topology field, in order of
+// preference. Custom labels from Options.TopologyLabels take precedence.
+var topologyLabels = map[string][]string{
+	"zone": {
+		corev1.LabelTopologyZone,
+		corev1.LabelFailureDomainBetaZone,
+	},
+	"region": {
+		corev1.LabelTopologyRegion,
+		corev1.LabelFailureDomainBetaRegion,
+	},
+	"instance_type": {
+		corev1.LabelInstanceTypeStable,
+		corev1.LabelInstanceType,
+	},
+	"arch": {
+		corev1.LabelArchStable,
+		"beta.kubernetes.io/arch",
+	},
+	"os": {
+		corev1.LabelOSStable,
+		"beta.kubernetes.io/os",
+	},
+	"provider_id": {},
+	"node_pool": {
+		"eks.amazonaws.com/nodegroup",    // EKS managed node groups
+		"cloud.google.com/gke-nodepool",  // GKE
+		"kubernetes.azure.com/agentpool", // AKS
+		"agentpool",                      // AKS, older clusters
+		"karpenter.sh/nodepool",          // Karpenter
+		"karpenter.sh/provisioner-name",  // Karpenter before v1beta1
+		"kops.k8s.io/instancegroup",      // kOps
+		"node.kubernetes.io/pool",        // Common convention on-prem
+		"alpha.eksctl.io/nodegroup-name", // EKS self-managed node groups created by eksctl
+	},
+}
+
+// ParseTopologyLabels parses a list of field=label pairs such as zone=example.com/rack
+// into a label mapping for Options.TopologyLabels
+func ParseTopologyLabels(items []string) (map[string]string, error) {
+	if len(items) == 0 {
+		return nil, nil
+	}
+	mapping := make(map[string]string, len(items))
+	for _, item := range items {
+		field, label, found := strings.Cut(item, "=")
+		field, label = strings.TrimSpace(field), strings.TrimSpace(label)
+		if !found || label == "" {
+			return nil, fmt.Errorf("invalid topology label %q, expected field=label", item)
+		}
+		if _, ok := topologyLabels[field]; !ok {
+			return nil, fmt.Errorf("unknown topology field %q", field)
+		}
+		mapping[field] = label
+	}
+	return mapping, nil
+}
+
+// topologyValue returns a topology field of a node from the custom label, the well-known
+// labels or fallback, in that order
+func (w *Watcher) topologyValue(n *corev1.Node, field, fallback string) string {
+	if label, ok := w.options.TopologyLabels[field]; ok {
+		if value := n.Labels[label]; value != "" {
+			return value
+		}
+	}
+	for _, label := range topologyLabels[field] {
+		if value := n.Labels[label]; value != "" {
+			return value
+		}
+	}
+	return fallback
+}
@@ -0,0 +1,293 @@
+package k8s
+
+import (
+	"sort"
+
+	"github.com/pettersolberg88/kube-ops-view-ng/internal/model"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// claimKey returns the cache key of a persistent volume claim
+func claimKey(namespace, name string) string {
+	return namespace + "/" + name
+}
+
+// podClaim is a pod volume backed by a persistent volume claim
+type podClaim struct {
+	volume string
+	claim  string
+}
+
+// podClaimNames returns the claims mounted by a pod. Generic ephemeral volumes are backed
+// by a claim named after the pod and the volume.
+func podClaimNames(p *corev1.Pod) []podClaim {
+	var claims []podClaim
+	for _, v := range p.Spec.Volumes {
+		if v.PersistentVolumeClaim != nil {
+			claims = append(claims, podClaim{volume: v.Name, claim: v.PersistentVolumeClaim.ClaimName})
+		} else if v.Ephemeral != nil {
+			claims = append(claims, podClaim{volume: v.Name, claim: p.Name + "-" + v.Name})
+		}
+	}
+	return claims
+}
+
+// podVolumeClaims returns the claims mounted by a pod with the state of the cached
+// claims. w.mu must be held.
+func (w *Watcher) podVolumeClaims(p *corev1.Pod) []model.VolumeClaim {
+	names := podClaimNames(p)
+	if len(names) == 0 {
+		return nil
+	}
+	claims := make([]model.VolumeClaim, 0, len(names))
+	for _, n := range names {
+		claim := model.VolumeClaim{Name: n.claim}
+		if cached, ok := w.claims[claimKey(p.Namespace, n.claim)]; ok {
+			claim = *cached
+		}
+		claim.Volume = n.volume
+		claims = append(claims, claim)
+	}
+	return claims
+}
+
+// trackPodClaims maintains the index of pods by the claims they mount. It is called when
+// a pod changes from oldClaims to newClaims. w.mu must be held.
+func (w *Watcher) trackPodClaims(namespace, pod string, oldClaims, newClaims []model.VolumeClaim) {
+	key := podKey(namespace, pod)
+	for _, c := range oldClaims {
+		ck := claimKey(namespace, c.Name)
+		delete(w.claimPods[ck], key)
+		if len(w.claimPods[ck]) == 0 {
+			delete(w.claimPods, ck)
+		}
+	}
+	for _, c := range newClaims {
+		ck := claimKey(namespace, c.Name)
+		if w.claimPods[ck] == nil {
+			w.claimPods[ck] = make(map[string]bool)
+		}
+		w.claimPods[ck][key] = true
+	}
+}
+
+func (w *Watcher) addClaim(obj interface{}) {
+	w.counters.countEvent("persistentvolumeclaim", "add")
+	pvc := obj.(*corev1.PersistentVolumeClaim)
+	w.setClaim(pvc.Namespace, pvc.Name, convertClaim(pvc))
+}
+
+func (w *Watcher) updateClaim(old, new interface{}) {
+	w.counters.countEvent("persistentvolumeclaim", "update")
+	pvc := new.(*corev1.PersistentVolumeClaim)
+	w.setClaim(pvc.Namespace, pvc.Name, convertClaim(pvc))
+}
+
+func (w *Watcher) deleteClaim(obj interface{}) {
+	w.counters.countEvent("persistentvolumeclaim", "delete")
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	pvc, ok := obj.(*corev1.PersistentVolumeClaim)
+	if !ok {
+		return
+	}
+	w.setClaim(pvc.Namespace, pvc.Name, nil)
+}
+
+// setClaim caches a claim, or forgets it if claim is nil, and updates the pods mounting it
+func (w *Watcher) setClaim(namespace, name string, claim *model.VolumeClaim) {
+	key := claimKey(namespace, name)
+	w.mu.Lock()
+	if claim == nil {
+		delete(w.claims, key)
+	} else {
+		w.claims[key] = claim
+	}
+
+	toBroadcast := false
+	for pk := range w.claimPods[key] {
+		existing, ok := w.pods[pk]
+		if !ok {
+			continue
+		}
+		claims := make([]model.VolumeClaim, len(existing.VolumeClaims))
+		for i, c := range existing.VolumeClaims {
+			if c.Name == name {
+				volume := c.Volume
+				c = model.VolumeClaim{Name: name}
+				if claim != nil {
+					c = *claim
+				}
+				c.Volume = volume
+			}
+			claims[i] = c
+		}
+		pod := copyPod(existing)
+		pod.VolumeClaims = claims
+		if pod.Equals(existing) {
+			continue
+		}
+		w.pods[pk] = pod
+		w.recordDelta(model.Delta{Type: model.DeltaPodUpsert, Pod: copyPod(pod)})
+		toBroadcast = true
+	}
+	w.mu.Unlock()
+	if toBroadcast {
+		w.broadcast()
+	}
+}
+
+// convertClaim converts a persistent volume claim, the capacity is the provisioned size
+// once bound and the requested size before
+func convertClaim(pvc *corev1.PersistentVolumeClaim) *model.VolumeClaim {
+	claim := &model.VolumeClaim{
+		Name:        pvc.Name,
+		Phase:       string(pvc.Status.Phase),
+		AccessModes: []string{},
+	}
+	if pvc.Spec.StorageClassName != nil {
+		claim.StorageClass = *pvc.Spec.StorageClassName
+	}
+	if q, ok := pvc.Status.Capacity[corev1.ResourceStorage]; ok {
+		claim.Capacity = q.String()
+	} else if q, ok := pvc.Spec.Resources.Requests[corev1.ResourceStorage]; ok {
+		claim.Capacity = q.String()
+	}
+	for _, mode := range pvc.Spec.AccessModes {
+		claim.AccessModes = append(claim.AccessModes, string(mode))
+	}
+	return claim
+}
+
+func (w *Watcher) addVolumeAttachment(obj interface{}) {
+	w.counters.countEvent("volumeattachment", "add")
+	w.setVolumeAttachment(nil, obj)
+}
+
+func (w *Watcher) updateVolumeAttachment(old, new interface{}) {
+	w.counters.countEvent("volumeattachment", "update")
+	w.setVolumeAttachment(old, new)
+}
+
+func (w *Watcher) deleteVolumeAttachment(obj interface{}) {
+	w.counters.countEvent("volumeattachment", "delete")
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	w.setVolumeAttachment(obj, nil)
+}
+
+// setVolumeAttachment replaces the old version of a VolumeAttachment with the new one,
+// either may be nil
+func (w *Watcher) setVolumeAttachment(old, new interface{}) {
+	nodes := make(map[string]bool)
+	w.mu.Lock()
+	if va, ok := old.(*storagev1.VolumeAttachment); ok {
+		delete(w.attachments[va.Spec.NodeName], va.Name)
+		if len(w.attachments[va.Spec.NodeName]) == 0 {
+			delete(w.attachments, va.Spec.NodeName)
+		}
+		nodes[va.Spec.NodeName] = true
+	}
+	if va, ok := new.(*storagev1.VolumeAttachment); ok {
+		if w.attachments[va.Spec.NodeName] == nil {
+			w.attachments[va.Spec.NodeName] = make(map[string]string)
+		}
+		w.attachments[va.Spec.NodeName][va.Name] = va.Spec.Attacher
+		nodes[va.Spec.NodeName] = true
+	}
+	toBroadcast := false
+	for name := range nodes {
+		toBroadcast = w.refreshNodeVolumes(name) || toBroadcast
+	}
+	w.mu.Unlock()
+	if toBroadcast {
+		w.broadcast()
+	}
+}
+
+func (w *Watcher) addCSINode(obj interface{}) {
+	w.counters.countEvent("csinode", "add")
+	w.setCSINode(obj.(*storagev1.CSINode), false)
+}
+
+func (w *Watcher) updateCSINode(old, new interface{}) {
+	w.counters.countEvent("csinode", "update")
+	w.setCSINode(new.(*storagev1.CSINode), false)
+}
+
+func (w *Watcher) deleteCSINode(obj interface{}) {
+	w.counters.countEvent("csinode", "delete")
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	if csiNode, ok := obj.(*storagev1.CSINode); ok {
+		w.setCSINode(csiNode, true)
+	}
+}
+
+// setCSINode caches the attach limits of the CSI drivers on a node
+func (w *Watcher) setCSINode(csiNode *storagev1.CSINode, deleted bool) {
+	limits := make(map[string]int)
+	for _, d := range csiNode.Spec.Drivers {
+		if d.Allocatable != nil && d.Allocatable.Count != nil {
+			limits[d.Name] = int(*d.Allocatable.Count)
+		} else {
+			limits[d.Name] = 0
+		}
+	}
+	w.mu.Lock()
+	if deleted {
+		delete(w.attachLimits, csiNode.Name)
+	} else {
+		w.attachLimits[csiNode.Name] = limits
+	}
+	toBroadcast := w.refreshNodeVolumes(csiNode.Name)
+	w.mu.Unlock()
+	if toBroadcast {
+		w.broadcast()
+	}
+}
+
+// nodeVolumes returns the volume attachments per CSI driver of a node, sorted by driver.
+// Drivers without attachments are included if they report an attach limit. w.mu must be held.
+func (w *Watcher) nodeVolumes(name string) []model.VolumeDriver {
+	attached := make(map[string]int)
+	for _, driver := range w.attachments[name] {
+		attached[driver]++
+	}
+	for driver, limit := range w.attachLimits[name] {
+		if _, ok := attached[driver]; !ok && limit > 0 {
+			attached[driver] = 0
+		}
+	}
+	if len(attached) == 0 {
+		return nil
+	}
+	drivers := make([]model.VolumeDriver, 0, len(attached))
+	for driver, count := range attached {
+		drivers = append(drivers, model.VolumeDriver{Driver: driver, Attached: count, Limit: w.attachLimits[name][driver]})
+	}
+	sort.Slice(drivers, func(i, j int) bool { return drivers[i].Driver < drivers[j].Driver })
+	return drivers
+}
+
+// refreshNodeVolumes updates the volume attachments of a cached node and reports whether
+// they changed. w.mu must be held.
+func (w *Watcher) refreshNodeVolumes(name string) bool {
+	existing, ok := w.nodes[name]
+	if !ok {
+		return false
+	}
+	node := copyNode(existing)
+	node.AttachedVolumes = w.nodeVolumes(name)
+	if node.Equals(existing) {
+		return false
+	}
+	w.nodes[name] = node
+	w.recordDelta(model.Delta{Type: model.DeltaNodeUpsert, Node: copyNode(node)})
+	return true
+}
@@ -14,23 +14,89 @@ import (
 	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/cache"
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
 	"k8s.io/metrics/pkg/client/clientset/versioned"
 )
 
+// Options configures what a Watcher lists and watches
+type Options struct {
+	// Namespaces restricts the watcher to these namespaces, all namespaces are watched when empty
+	Namespaces []string
+	// SkipNodes disables the node informer, nodes are then derived from the pods scheduled on them
+	SkipNodes bool
+	// HistoryRetention is how long past states are kept for /api/history, history is disabled when zero
+	HistoryRetention time.Duration
+	// HistoryMaxBytes limits the approximate memory used by history, unlimited when zero
+	HistoryMaxBytes int64
+	// TopologyLabels maps node topology fields such as zone to custom node labels, which
+	// take precedence over the well-known labels
+	TopologyLabels map[string]string
+}
+
 // Watcher watches Kubernetes resources and maintains a local cache
 type Watcher struct {
+	cluster       string
+	options       Options
 	client        *kubernetes.Clientset
 	metricsClient *versioned.Clientset
-	factory       informers.SharedInformerFactory
+	factory       informers.SharedInformerFactory   // cluster-scoped resources
+	nsFactories   []informers.SharedInformerFactory // namespaced resources, one per watched namespace
 
 	// Local cache
-	mu    sync.RWMutex
-	nodes map[string]*model.Node
-	pods  map[string]*model.Pod
+	mu sync.RWMutex
+	objects
+	nodeRefs map[string]int          // pods per node, only used with SkipNodes
+	events   map[string][]eventEntry // keyed by eventKey(kind, namespace, name), newest first
+	owners   map[string]workloadRef  // controllers of ReplicaSets and Jobs, keyed by workloadKey(kind, namespace, name)
+
+	// Events waiting to be applied to their pods and nodes, keyed by eventKey
+	staleEvents map[string]eventObject
+	eventsTimer *time.Timer // flushes staleEvents, nil while none are waiting
+
+	workloadPods   map[string]map[string]bool // pods of a workload, keyed by workloadKey of the pod workload then podKey
+	controllerPods map[string]map[string]bool // pods of a direct controller such as a ReplicaSet, keyed by workloadKey then podKey
+	podControllers map[string]string          // workloadKey of the direct controller, keyed by podKey
+
+	// Rollout tracking, keyed by workloadKey(kind, namespace, name)
+	replicaSets      map[string]map[string]replicaSetRevision // revisions of Deployments by ReplicaSet name
+	currentRevisions map[string]string                        // current revision of Deployments and StatefulSets
+	staleRollouts    map[string]bool                          // rollouts to recompute before the next broadcast
+
+	// Service endpoints
+	endpointSlices map[string]map[string][]endpoint       // keyed by serviceKey(namespace, name), then slice name
+	podEndpoints   map[string]map[string]model.PodService // keyed by podKey(namespace, name), then slice name
+
+	// Volumes
+	claims       map[string]*model.VolumeClaim // keyed by claimKey(namespace, name)
+	claimPods    map[string]map[string]bool    // pods mounting a claim, keyed by claimKey then podKey
+	attachments  map[string]map[string]string  // CSI driver of VolumeAttachments, keyed by node then attachment name
+	attachLimits map[string]map[string]int     // attach limits from CSINode, keyed by node then driver
+
+	// Node allocation
+	podAllocations map[string]*podAllocation      // keyed by podKey(namespace, name)
+	nodeRequests   map[string]corev1.ResourceList // requests of the pods on a node, keyed by node
+	nodeLimits     map[string]corev1.ResourceList // limits of the pods on a node, keyed by node
+	nodePods       map[string]int                 // non-terminal pods on a node, keyed by node
+
+	// Delta tracking, guarded by mu
+	revision      uint64
+	pendingDeltas []model.Delta
+
+	// Past states, nil if disabled
+	history *history
+
+	// Connection state, guarded by mu
+	synced    bool
+	lastError error
+
+	// Telemetry
+	counters counters
 
 	// Event broadcasting
 	subscribersMu    sync.RWMutex
 	subscribers      []chan model.ClusterState
+	deltaSubscribers []chan []model.Delta
+	forward          chan model.ClusterState // Subscriber of the ClusterSet, not counted as a broadcast
 	pendingBroadcast bool
 	timer            *time.Timer
 
@@ -41,18 +107,56 @@ type Watcher struct {
 	shortInterval time.Duration
 }
 
-// NewWatcher creates a new Watcher
-func NewWatcher(client *kubernetes.Clientset, metricsClient *versioned.Clientset) *Watcher {
+// NewWatcher creates a new Watcher for the named cluster
+func NewWatcher(cluster string, client *kubernetes.Clientset, metricsClient *versioned.Clientset, options Options) *Watcher {
+	factory := informers.NewSharedInformerFactory(client, time.Minute*10)
+	nsFactories := []informers.SharedInformerFactory{factory}
+	if len(options.Namespaces) > 0 {
+		nsFactories = make([]informers.SharedInformerFactory, 0, len(options.Namespaces))
+		for _, ns := range options.Namespaces {
+			nsFactories = append(nsFactories, informers.NewSharedInformerFactoryWithOptions(client, time.Minute*10, informers.WithNamespace(ns)))
+		}
+	}
+
+	var h *history
+	if options.HistoryRetention > 0 {
+		h = newHistory(options.HistoryRetention, options.HistoryMaxBytes)
+	}
+
 	return &Watcher{
-		client:        client,
-		metricsClient: metricsClient,
-		factory:       informers.NewSharedInformerFactory(client, time.Minute*10),
-		nodes:         make(map[string]*model.Node),
-		pods:          make(map[string]*model.Pod),
-		subscribers:   make([]chan model.ClusterState, 0),
-		timer:         nil,
-		interval:      time.Second / 5,  // Wait before sending a broadcast after the last sent
-		shortInterval: time.Second / 20, // Wait before sending a broadcast after the first received
+		cluster:          cluster,
+		history:          h,
+		options:          options,
+		client:           client,
+		metricsClient:    metricsClient,
+		factory:          factory,
+		nsFactories:      nsFactories,
+		objects:          newObjects(),
+		nodeRefs:         make(map[string]int),
+		events:           make(map[string][]eventEntry),
+		staleEvents:      make(map[string]eventObject),
+		owners:           make(map[string]workloadRef),
+		workloadPods:     make(map[string]map[string]bool),
+		controllerPods:   make(map[string]map[string]bool),
+		podControllers:   make(map[string]string),
+		replicaSets:      make(map[string]map[string]replicaSetRevision),
+		currentRevisions: make(map[string]string),
+		staleRollouts:    make(map[string]bool),
+		endpointSlices:   make(map[string]map[string][]endpoint),
+		podEndpoints:     make(map[string]map[string]model.PodService),
+		claims:           make(map[string]*model.VolumeClaim),
+		claimPods:        make(map[string]map[string]bool),
+		attachments:      make(map[string]map[string]string),
+		attachLimits:     make(map[string]map[string]int),
+		podAllocations:   make(map[string]*podAllocation),
+		nodeRequests:     make(map[string]corev1.ResourceList),
+		nodeLimits:       make(map[string]corev1.ResourceList),
+		nodePods:         make(map[string]int),
+		subscribers:      make([]chan model.ClusterState, 0),
+		deltaSubscribers: make([]chan []model.Delta, 0),
+		timer:            nil,
+		interval:         time.Second / 5,  // Wait before sending a broadcast after the last sent
+		shortInterval:    time.Second / 20, // Wait before sending a broadcast after the first received
 	}
 }
 
@@ -71,6 +175,9 @@ func (w *Watcher) Unsubscribe(ch chan model.ClusterState) {
 	w.subscribersMu.Lock()
 	defer w.subscribersMu.Unlock()
 
+	if ch == w.forward {
+		w.forward = nil
+	}
 	for i, sub := range w.subscribers {
 		if sub == ch {
 			close(ch)
@@ -80,6 +187,41 @@ func (w *Watcher) Unsubscribe(ch chan model.ClusterState) {
 	}
 }
 
+// subscribeForward returns a channel that receives cluster state updates for the merged
+// stream. Deliveries to it are internal and left out of the broadcast counters.
+func (w *Watcher) subscribeForward() chan model.ClusterState {
+	ch := w.Subscribe()
+	w.subscribersMu.Lock()
+	defer w.subscribersMu.Unlock()
+
+	w.forward = ch
+	return ch
+}
+
+// SubscribeDeltas returns a channel that receives batches of incremental changes
+func (w *Watcher) SubscribeDeltas() chan []model.Delta {
+	w.subscribersMu.Lock()
+	defer w.subscribersMu.Unlock()
+
+	ch := make(chan []model.Delta, 10)
+	w.deltaSubscribers = append(w.deltaSubscribers, ch)
+	return ch
+}
+
+// UnsubscribeDeltas removes a delta subscriber channel
+func (w *Watcher) UnsubscribeDeltas(ch chan []model.Delta) {
+	w.subscribersMu.Lock()
+	defer w.subscribersMu.Unlock()
+
+	for i, sub := range w.deltaSubscribers {
+		if sub == ch {
+			close(ch)
+			w.deltaSubscribers = append(w.deltaSubscribers[:i], w.deltaSubscribers[i+1:]...)
+			break
+		}
+	}
+}
+
 // scheduleBroadcast schedules a broadcast after the throttle interval
 func (w *Watcher) scheduleBroadcast() {
 	w.subscribersMu.Lock()
@@ -99,16 +241,7 @@ func (w *Watcher) scheduleBroadcast() {
 				w.subscribersMu.Lock()
 				w.pending = false
 				w.subscribersMu.Unlock()
-				state := w.GetSnapshot()
-				w.subscribersMu.RLock()
-				for _, ch := range w.subscribers {
-					select {
-					case ch <- state:
-					default:
-						// Skip if channel is full
-					}
-				}
-				w.subscribersMu.RUnlock()
+				w.send()
 			})
 		}
 		w.timer = time.AfterFunc(w.interval, func() {
@@ -120,16 +253,7 @@ func (w *Watcher) scheduleBroadcast() {
 			}
 			w.subscribersMu.Unlock()
 			if isPending {
-				state := w.GetSnapshot()
-				w.subscribersMu.RLock()
-				for _, ch := range w.subscribers {
-					select {
-					case ch <- state:
-					default:
-						// Skip if channel is full
-					}
-				}
-				w.subscribersMu.RUnlock()
+				w.send()
 			}
 			w.subscribersMu.Lock()
 			w.active = false
@@ -138,36 +262,306 @@ func (w *Watcher) scheduleBroadcast() {
 	}
 }
 
+// send delivers the current state and the pending deltas to all subscribers
+func (w *Watcher) send() {
+	deltas := w.takeDeltas()
+	state := w.GetSnapshot()
+	w.subscribersMu.RLock()
+	for _, ch := range w.subscribers {
+		select {
+		case ch <- state:
+			if ch != w.forward {
+				w.counters.countBroadcast(true)
+			}
+		default:
+			// Skip if channel is full
+			if ch != w.forward {
+				w.counters.countBroadcast(false)
+			}
+		}
+	}
+	if len(deltas) > 0 {
+		for _, ch := range w.deltaSubscribers {
+			select {
+			case ch <- deltas:
+				w.counters.countBroadcast(true)
+			default:
+				// Skip if channel is full, the subscriber detects the revision gap
+				w.counters.countBroadcast(false)
+			}
+		}
+	}
+	w.subscribersMu.RUnlock()
+}
+
+// recordDelta assigns the next revision to a change and queues it for broadcast, w.mu must be held
+func (w *Watcher) recordDelta(d model.Delta) {
+	now := time.Now()
+	w.revision++
+	d.Cluster = w.cluster
+	d.Revision = w.revision
+	d.Time = now.Format(time.RFC3339Nano)
+	w.pendingDeltas = append(w.pendingDeltas, d)
+	if w.history != nil {
+		w.history.add(now, d)
+	}
+}
+
+// takeDeltas refreshes the rollouts and returns and clears the deltas queued since the
+// last broadcast
+func (w *Watcher) takeDeltas() []model.Delta {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.refreshRollouts()
+	deltas := w.pendingDeltas
+	w.pendingDeltas = nil
+	return deltas
+}
+
 // broadcast schedules sending the current state to all subscribers
 func (w *Watcher) broadcast() {
 	w.scheduleBroadcast()
 }
 
+// optionalSyncTimeout is how long to wait for informers that are not needed to show nodes
+// and pods before the watcher reports itself ready without them
+const optionalSyncTimeout = 30 * time.Second
+
+// optionalInformer is an informer of a feature that is left out when it cannot sync, e.g.
+// because RBAC does not grant access to its resource
+type optionalInformer struct {
+	resource string
+	informer cache.SharedIndexInformer
+}
+
 // Start starts the watcher
 func (w *Watcher) Start(stopCh <-chan struct{}) {
-	nodeInformer := w.factory.Core().V1().Nodes().Informer()
-	podInformer := w.factory.Core().V1().Pods().Informer()
+	var required []cache.InformerSynced
+	var optional []optionalInformer
 
-	nodeInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
-		AddFunc:    w.addNode,
-		UpdateFunc: w.updateNode,
-		DeleteFunc: w.deleteNode,
-	})
+	if !w.options.SkipNodes {
+		nodeInformer := w.factory.Core().V1().Nodes().Informer()
+		nodeInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+			AddFunc:    w.addNode,
+			UpdateFunc: w.updateNode,
+			DeleteFunc: w.deleteNode,
+		})
+		required = append(required, nodeInformer.HasSynced)
 
-	podInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
-		AddFunc:    w.addPod,
-		UpdateFunc: w.updatePod,
-		DeleteFunc: w.deletePod,
-	})
+		storage := w.factory.Storage().V1()
+		attachmentInformer := storage.VolumeAttachments().Informer()
+		attachmentInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+			AddFunc:    w.addVolumeAttachment,
+			UpdateFunc: w.updateVolumeAttachment,
+			DeleteFunc: w.deleteVolumeAttachment,
+		})
+		csiNodeInformer := storage.CSINodes().Informer()
+		csiNodeInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+			AddFunc:    w.addCSINode,
+			UpdateFunc: w.updateCSINode,
+			DeleteFunc: w.deleteCSINode,
+		})
+		optional = append(optional,
+			optionalInformer{"volumeattachments", attachmentInformer},
+			optionalInformer{"csinodes", csiNodeInformer})
+	}
 
-	w.factory.Start(stopCh)
-	w.factory.WaitForCacheSync(stopCh)
+	for i, factory := range w.nsFactories {
+		scope := ""
+		if len(w.options.Namespaces) > 0 {
+			scope = " in namespace " + w.options.Namespaces[i]
+		}
+
+		podInformer := factory.Core().V1().Pods().Informer()
+		podInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+			AddFunc:    w.addPod,
+			UpdateFunc: w.updatePod,
+			DeleteFunc: w.deletePod,
+		})
+		required = append(required, podInformer.HasSynced)
+
+		eventInformer := factory.Core().V1().Events().Informer()
+		eventInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+			AddFunc:    w.addEvent,
+			UpdateFunc: w.updateEvent,
+			DeleteFunc: w.deleteEvent,
+		})
+
+		apps := factory.Apps().V1()
+		apps.Deployments().Informer().AddEventHandler(w.workloadHandlers("Deployment", convertDeployment))
+		apps.StatefulSets().Informer().AddEventHandler(w.workloadHandlers("StatefulSet", convertStatefulSet))
+		apps.DaemonSets().Informer().AddEventHandler(w.workloadHandlers("DaemonSet", convertDaemonSet))
+		apps.ReplicaSets().Informer().AddEventHandler(w.workloadHandlers("ReplicaSet", convertReplicaSet))
+		apps.ReplicaSets().Informer().AddEventHandler(w.ownerHandlers("ReplicaSet"))
+		apps.ReplicaSets().Informer().AddEventHandler(w.replicaSetRevisionHandlers())
+		apps.StatefulSets().Informer().AddEventHandler(w.statefulSetRevisionHandlers())
+
+		serviceInformer := factory.Core().V1().Services().Informer()
+		serviceInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+			AddFunc:    w.addService,
+			UpdateFunc: w.updateService,
+			DeleteFunc: w.deleteService,
+		})
+		endpointSliceInformer := factory.Discovery().V1().EndpointSlices().Informer()
+		endpointSliceInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+			AddFunc:    w.addEndpointSlice,
+			UpdateFunc: w.updateEndpointSlice,
+			DeleteFunc: w.deleteEndpointSlice,
+		})
+		claimInformer := factory.Core().V1().PersistentVolumeClaims().Informer()
+		claimInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+			AddFunc:    w.addClaim,
+			UpdateFunc: w.updateClaim,
+			DeleteFunc: w.deleteClaim,
+		})
+		jobInformer := factory.Batch().V1().Jobs().Informer()
+		jobInformer.AddEventHandler(w.workloadHandlers("Job", convertJob))
+		jobInformer.AddEventHandler(w.ownerHandlers("Job"))
+
+		optional = append(optional,
+			optionalInformer{"events" + scope, eventInformer},
+			optionalInformer{"deployments" + scope, apps.Deployments().Informer()},
+			optionalInformer{"statefulsets" + scope, apps.StatefulSets().Informer()},
+			optionalInformer{"daemonsets" + scope, apps.DaemonSets().Informer()},
+			optionalInformer{"replicasets" + scope, apps.ReplicaSets().Informer()},
+			optionalInformer{"services" + scope, serviceInformer},
+			optionalInformer{"endpointslices" + scope, endpointSliceInformer},
+			optionalInformer{"persistentvolumeclaims" + scope, claimInformer},
+			optionalInformer{"jobs" + scope, jobInformer})
+	}
+
+	go w.probeHealth(stopCh)
+
+	if !w.startFactories(stopCh, required, optional) {
+		return
+	}
+
+	w.mu.Lock()
+	w.synced = true
+	w.mu.Unlock()
+	w.broadcast()
 
 	if w.metricsClient != nil {
 		go w.pollMetrics(stopCh)
 	}
 }
 
+// startFactories starts all informer factories and waits for the required informers to
+// sync. Optional informers get optionalSyncTimeout, those that have not synced by then
+// keep retrying in the background while the watcher carries on without them. It returns
+// false if stopCh was closed before the informers synced.
+func (w *Watcher) startFactories(stopCh <-chan struct{}, required []cache.InformerSynced, optional []optionalInformer) bool {
+	w.factory.Start(stopCh)
+	for _, factory := range w.nsFactories {
+		factory.Start(stopCh)
+	}
+	if !cache.WaitForCacheSync(stopCh, required...) {
+		return false
+	}
+
+	expired := make(chan struct{})
+	timer := time.AfterFunc(optionalSyncTimeout, func() { close(expired) })
+	defer timer.Stop()
+	for _, o := range optional {
+		if !waitForSync(stopCh, expired, o.informer.HasSynced) {
+			select {
+			case <-stopCh:
+				return false
+			default:
+			}
+			log.Printf("[%s] Not synced %s after %s, continuing without it. Check that RBAC grants list and watch on it.", w.cluster, o.resource, optionalSyncTimeout)
+		}
+	}
+	return true
+}
+
+// waitForSync waits until synced returns true and reports whether it did before stopCh or
+// expired was closed
+func waitForSync(stopCh, expired <-chan struct{}, synced cache.InformerSynced) bool {
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	for !synced() {
+		select {
+		case <-stopCh:
+			return false
+		case <-expired:
+			return false
+		case <-ticker.C:
+		}
+	}
+	return true
+}
+
+// metricsNamespaces returns the namespaces to list pod metrics in
+func (w *Watcher) metricsNamespaces() []string {
+	if len(w.options.Namespaces) == 0 {
+		return []string{metav1.NamespaceAll}
+	}
+	return w.options.Namespaces
+}
+
+// Cluster returns the name of the watched cluster
+func (w *Watcher) Cluster() string {
+	return w.cluster
+}
+
+// Info returns the connection state of the watched cluster
+func (w *Watcher) Info() model.ClusterInfo {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	info := model.ClusterInfo{
+		Name:     w.cluster,
+		Status:   model.ClusterSyncing,
+		Revision: w.revision,
+		Nodes:    len(w.nodes),
+		Pods:     len(w.pods),
+	}
+	if w.synced {
+		info.Status = model.ClusterReady
+	}
+	if w.lastError != nil {
+		info.Status = model.ClusterDegraded
+		info.Error = w.lastError.Error()
+	}
+	if oldest := w.HistoryOldest(); !oldest.IsZero() {
+		info.HistoryFrom = oldest.Format(time.RFC3339Nano)
+	}
+	return info
+}
+
+// probeHealth periodically checks that the API server is reachable, marking the cluster as degraded when it is not
+func (w *Watcher) probeHealth(stopCh <-chan struct{}) {
+	ticker := time.NewTicker(30 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		err := w.client.Discovery().RESTClient().Get().AbsPath("/version").Do(ctx).Error()
+		cancel()
+
+		w.mu.Lock()
+		changed := (err == nil) != (w.lastError == nil)
+		w.lastError = err
+		w.mu.Unlock()
+		if changed {
+			if err != nil {
+				log.Printf("[%s] Cluster is unreachable: %v", w.cluster, err)
+			} else {
+				log.Printf("[%s] Cluster is reachable", w.cluster)
+			}
+			w.broadcast()
+		}
+
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
 func (w *Watcher) pollMetrics(stopCh <-chan struct{}) {
 	ticker := time.NewTicker(10 * time.Second)
 	defer ticker.Stop()
@@ -187,47 +581,98 @@ func (w *Watcher) pollMetrics(stopCh <-chan struct{}) {
 
 func (w *Watcher) updateMetrics() {
 	ctx := context.Background()
+	start := time.Now()
+	failures := 0
+	defer func() {
+		w.counters.countMetricsPoll(time.Since(start), failures)
+	}()
 
-	// Node metrics
-	nodeMetrics, err := w.metricsClient.MetricsV1beta1().NodeMetricses().List(ctx, metav1.ListOptions{})
+	// Node metrics, listing them needs the same cluster-wide access as listing nodes
+	var nodeMetrics *metricsv1beta1.NodeMetricsList
+	var err error
+	if !w.options.SkipNodes {
+		nodeMetrics, err = w.metricsClient.MetricsV1beta1().NodeMetricses().List(ctx, metav1.ListOptions{})
+	}
 	if err != nil {
-		log.Printf("Error fetching node metrics: %v", err)
-	} else {
+		failures++
+		log.Printf("[%s] Error fetching node metrics: %v", w.cluster, err)
+	} else if nodeMetrics != nil {
 		w.mu.Lock()
+		changed := make(map[string]model.Metrics)
 		for _, m := range nodeMetrics.Items {
 			if node, ok := w.nodes[m.Name]; ok {
-				node.Metrics = &model.Metrics{
-					CPU:    m.Usage.Cpu().String(),
-					Memory: m.Usage.Memory().String(),
+				metrics := convertMetrics(m.Usage.Cpu(), m.Usage.Memory())
+				if node.Metrics == nil || !node.Metrics.Equals(*metrics) {
+					changed[m.Name] = *metrics
+					updated := copyNode(node)
+					updated.Metrics = metrics
+					updated.Allocation = withUsage(node.Allocation, metrics)
+					w.nodes[m.Name] = updated
 				}
 			}
 		}
+		if len(changed) > 0 {
+			w.recordDelta(model.Delta{Type: model.DeltaMetrics, Metrics: &model.MetricsDelta{Nodes: changed}})
+		}
 		w.mu.Unlock()
 		w.broadcast()
 	}
 
 	// Pod metrics
-	podMetrics, err := w.metricsClient.MetricsV1beta1().PodMetricses(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
-	if err != nil {
-		log.Printf("Error fetching pod metrics: %v", err)
-	} else {
+	var podMetrics []metricsv1beta1.PodMetrics
+	fetched := false
+	for _, ns := range w.metricsNamespaces() {
+		list, err := w.metricsClient.MetricsV1beta1().PodMetricses(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			failures++
+			log.Printf("[%s] Error fetching pod metrics: %v", w.cluster, err)
+			continue
+		}
+		podMetrics = append(podMetrics, list.Items...)
+		fetched = true
+	}
+	if fetched {
 		w.mu.Lock()
-		for _, m := range podMetrics.Items {
-			if pod, ok := w.pods[m.Name]; ok {
+		changed := make(map[string]model.Metrics)
+		changedContainers := make(map[string]map[string]model.Metrics)
+		for _, m := range podMetrics {
+			key := podKey(m.Namespace, m.Name)
+			if pod, ok := w.pods[key]; ok {
 				cpu := resource.NewQuantity(0, resource.DecimalSI)
 				mem := resource.NewQuantity(0, resource.BinarySI)
+				containerMetrics := make(map[string]model.Metrics, len(m.Containers))
 
 				for _, c := range m.Containers {
 					cpu.Add(*c.Usage.Cpu())
 					mem.Add(*c.Usage.Memory())
+					containerMetrics[c.Name] = *convertMetrics(c.Usage.Cpu(), c.Usage.Memory())
 				}
 
-				pod.Metrics = &model.Metrics{
-					CPU:    cpu.String(),
-					Memory: mem.String(),
+				metrics := convertMetrics(cpu, mem)
+				if pod.Metrics == nil || !pod.Metrics.Equals(*metrics) {
+					changed[key] = *metrics
 				}
+
+				// Containers are shared with snapshots, so replace rather than modify them
+				containers, containerChanged := withContainerMetrics(pod.Containers, containerMetrics)
+				initContainers, initChanged := withContainerMetrics(pod.InitContainers, containerMetrics)
+				for name, cm := range initChanged {
+					containerChanged[name] = cm
+				}
+				if len(containerChanged) > 0 {
+					changedContainers[key] = containerChanged
+				}
+
+				updated := copyPod(pod)
+				updated.Metrics = metrics
+				updated.Containers = containers
+				updated.InitContainers = initContainers
+				w.pods[key] = updated
 			}
 		}
+		if len(changed) > 0 || len(changedContainers) > 0 {
+			w.recordDelta(model.Delta{Type: model.DeltaMetrics, Metrics: &model.MetricsDelta{Pods: changed, Containers: changedContainers}})
+		}
 		w.mu.Unlock()
 		w.broadcast()
 	}
@@ -238,49 +683,169 @@ func (w *Watcher) GetSnapshot() model.ClusterState {
 	w.mu.RLock()
 	defer w.mu.RUnlock()
 
-	nodes := make([]model.Node, 0, len(w.nodes))
-	for _, n := range w.nodes {
+	return w.buildState(w.objects, w.revision)
+}
+
+// buildState returns the sorted cluster state of a set of cached objects
+func (w *Watcher) buildState(o objects, revision uint64) model.ClusterState {
+	nodes := make([]model.Node, 0, len(o.nodes))
+	for _, n := range o.nodes {
 		nodes = append(nodes, *n)
 	}
 	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Name < nodes[j].Name })
 
-	pods := make([]model.Pod, 0, len(w.pods))
-	for _, p := range w.pods {
+	pods := make([]model.Pod, 0, len(o.pods))
+	for _, p := range o.pods {
 		pods = append(pods, *p)
 	}
-	sort.Slice(pods, func(i, j int) bool { return pods[i].Name < pods[j].Name })
+	sort.Slice(pods, func(i, j int) bool {
+		if pods[i].Namespace != pods[j].Namespace {
+			return pods[i].Namespace < pods[j].Namespace
+		}
+		return pods[i].Name < pods[j].Name
+	})
+
+	workloads := make([]model.Workload, 0, len(o.workloads))
+	for _, wl := range o.workloads {
+		workloads = append(workloads, *wl)
+	}
+	sort.Slice(workloads, func(i, j int) bool {
+		if workloads[i].Namespace != workloads[j].Namespace {
+			return workloads[i].Namespace < workloads[j].Namespace
+		}
+		if workloads[i].Kind != workloads[j].Kind {
+			return workloads[i].Kind < workloads[j].Kind
+		}
+		return workloads[i].Name < workloads[j].Name
+	})
+
+	rollouts := make([]model.Rollout, 0, len(o.rollouts))
+	for _, r := range o.rollouts {
+		rollouts = append(rollouts, *r)
+	}
+	sort.Slice(rollouts, func(i, j int) bool {
+		if rollouts[i].Namespace != rollouts[j].Namespace {
+			return rollouts[i].Namespace < rollouts[j].Namespace
+		}
+		if rollouts[i].Kind != rollouts[j].Kind {
+			return rollouts[i].Kind < rollouts[j].Kind
+		}
+		return rollouts[i].Name < rollouts[j].Name
+	})
+
+	services := make([]model.Service, 0, len(o.services))
+	for _, svc := range o.services {
+		services = append(services, *svc)
+	}
+	sort.Slice(services, func(i, j int) bool {
+		if services[i].Namespace != services[j].Namespace {
+			return services[i].Namespace < services[j].Namespace
+		}
+		return services[i].Name < services[j].Name
+	})
 
 	return model.ClusterState{
-		Nodes: nodes,
-		Pods:  pods,
+		Cluster:   w.cluster,
+		Revision:  revision,
+		Nodes:     nodes,
+		Pods:      pods,
+		Workloads: workloads,
+		Rollouts:  rollouts,
+		Services:  services,
+	}
+}
+
+// podKey returns the namespace-qualified cache key for a pod
+func podKey(namespace, name string) string {
+	return namespace + "/" + name
+}
+
+// copyNode returns a shallow copy of a cached node, safe to hand out after w.mu is released
+func copyNode(n *model.Node) *model.Node {
+	c := *n
+	return &c
+}
+
+// copyPod returns a shallow copy of a cached pod, safe to hand out after w.mu is released
+func copyPod(p *model.Pod) *model.Pod {
+	c := *p
+	return &c
+}
+
+// trackPodNode maintains placeholder nodes for the nodes pods are scheduled on when
+// nodes are not watched. It is called when a pod moves from oldNode to newNode, with
+// an empty name for an unscheduled or deleted pod. w.mu must be held.
+func (w *Watcher) trackPodNode(oldNode, newNode string) {
+	if !w.options.SkipNodes || oldNode == newNode {
+		return
+	}
+	if oldNode != "" {
+		w.nodeRefs[oldNode]--
+		if w.nodeRefs[oldNode] <= 0 {
+			delete(w.nodeRefs, oldNode)
+			if existing, ok := w.nodes[oldNode]; ok {
+				delete(w.nodes, oldNode)
+				w.recordDelta(model.Delta{Type: model.DeltaNodeDelete, Node: copyNode(existing)})
+			}
+		}
+	}
+	if newNode != "" {
+		w.nodeRefs[newNode]++
+		if _, ok := w.nodes[newNode]; !ok {
+			node := &model.Node{
+				Cluster:     w.cluster,
+				Name:        newNode,
+				Status:      "Unknown",
+				Conditions:  []model.NodeCondition{},
+				Severity:    model.SeverityOK,
+				Taints:      []model.Taint{},
+				Roles:       []string{},
+				Labels:      map[string]string{},
+				Capacity:    map[string]string{},
+				Allocatable: map[string]string{},
+				Allocation:  w.nodeAllocation(newNode, 0, nil),
+			}
+			w.nodes[newNode] = node
+			w.recordDelta(model.Delta{Type: model.DeltaNodeUpsert, Node: copyNode(node)})
+		}
 	}
 }
 
 // Event Handlers
 
 func (w *Watcher) addNode(obj interface{}) {
+	w.counters.countEvent("node", "add")
 	node := obj.(*corev1.Node)
 	w.mu.Lock()
-	w.nodes[node.Name] = w.convertNode(node)
+	newNode := w.convertNode(node)
+	newNode.RecentEvents = w.recentEvents("Node", "", node.Name)
+	w.nodes[node.Name] = newNode
+	w.recordDelta(model.Delta{Type: model.DeltaNodeUpsert, Node: copyNode(newNode)})
 	w.mu.Unlock()
 	w.broadcast()
 }
 
 func (w *Watcher) updateNode(old, new interface{}) {
+	w.counters.countEvent("node", "update")
 	node := new.(*corev1.Node)
 	w.mu.Lock()
 	newNode2 := w.convertNode(node)
+	newNode2.RecentEvents = w.recentEvents("Node", "", node.Name)
 	existing2, exists := w.nodes[node.Name]
-	toBroadcast := false
+	toBroadcast := !exists
 	if exists {
 		if existing2.Metrics != nil {
 			newNode2.Metrics = existing2.Metrics
+			newNode2.Allocation = withUsage(newNode2.Allocation, existing2.Metrics)
 		}
 		if !newNode2.Equals(existing2) {
 			toBroadcast = true
 		}
 	}
 	w.nodes[node.Name] = newNode2
+	if toBroadcast {
+		w.recordDelta(model.Delta{Type: model.DeltaNodeUpsert, Node: copyNode(newNode2)})
+	}
 	w.mu.Unlock()
 	if toBroadcast {
 		w.broadcast()
@@ -288,6 +853,7 @@ func (w *Watcher) updateNode(old, new interface{}) {
 }
 
 func (w *Watcher) deleteNode(obj interface{}) {
+	w.counters.countEvent("node", "delete")
 	node, ok := obj.(*corev1.Node)
 	if !ok {
 		// Could be DeletedFinalStateUnknown
@@ -301,42 +867,86 @@ func (w *Watcher) deleteNode(obj interface{}) {
 		}
 	}
 	w.mu.Lock()
-	delete(w.nodes, node.Name)
+	if existing, ok := w.nodes[node.Name]; ok {
+		delete(w.nodes, node.Name)
+		w.recordDelta(model.Delta{Type: model.DeltaNodeDelete, Node: copyNode(existing)})
+	}
 	w.mu.Unlock()
 	w.broadcast()
 }
 
 func (w *Watcher) addPod(obj interface{}) {
+	w.counters.countEvent("pod", "add")
 	pod := obj.(*corev1.Pod)
 	w.mu.Lock()
-	w.pods[pod.Name] = w.convertPod(pod)
+	newPod := w.convertPod(pod)
+	newPod.RecentEvents = w.recentEvents("Pod", pod.Namespace, pod.Name)
+	key := podKey(pod.Namespace, pod.Name)
+	oldNode := ""
+	var oldClaims []model.VolumeClaim
+	existing, exists := w.pods[key]
+	if exists {
+		oldNode = existing.NodeName
+		oldClaims = existing.VolumeClaims
+		w.markRollout(existing)
+	}
+	w.trackPodWorkload(key, existing, newPod)
+	w.trackPodController(key, pod)
+	w.trackPodNode(oldNode, newPod.NodeName)
+	w.trackPodClaims(pod.Namespace, pod.Name, oldClaims, newPod.VolumeClaims)
+	w.trackPodAllocation(key, pod)
+	w.markRollout(newPod)
+	w.pods[key] = newPod
+	w.recordDelta(model.Delta{Type: model.DeltaPodUpsert, Pod: copyPod(newPod)})
 	w.mu.Unlock()
 	w.broadcast()
 }
 
 func (w *Watcher) updatePod(old, new interface{}) {
+	w.counters.countEvent("pod", "update")
 	pod := new.(*corev1.Pod)
 	w.mu.Lock()
 	// Preserve metrics
 	newPod2 := w.convertPod(pod)
-	existing2, exists := w.pods[pod.Name]
-	toBroadcast := false
+	newPod2.RecentEvents = w.recentEvents("Pod", pod.Namespace, pod.Name)
+	key := podKey(pod.Namespace, pod.Name)
+	existing2, exists := w.pods[key]
+	toBroadcast := !exists
+	oldNode := ""
+	var oldClaims []model.VolumeClaim
 	if exists {
+		oldNode = existing2.NodeName
+		oldClaims = existing2.VolumeClaims
 		if existing2.Metrics != nil {
 			newPod2.Metrics = existing2.Metrics
 		}
+		preserveContainerMetrics(newPod2.Containers, existing2.Containers)
+		preserveContainerMetrics(newPod2.InitContainers, existing2.InitContainers)
 		if !newPod2.Equals(existing2) {
 			toBroadcast = true
+			w.markRollout(existing2)
 		}
 	}
-	w.pods[pod.Name] = newPod2
-	w.mu.Unlock()
 	if toBroadcast {
+		w.markRollout(newPod2)
+	}
+	w.trackPodWorkload(key, existing2, newPod2)
+	w.trackPodController(key, pod)
+	w.trackPodNode(oldNode, newPod2.NodeName)
+	w.trackPodClaims(pod.Namespace, pod.Name, oldClaims, newPod2.VolumeClaims)
+	nodeChanged := w.trackPodAllocation(key, pod)
+	w.pods[key] = newPod2
+	if toBroadcast {
+		w.recordDelta(model.Delta{Type: model.DeltaPodUpsert, Pod: copyPod(newPod2)})
+	}
+	w.mu.Unlock()
+	if toBroadcast || nodeChanged {
 		w.broadcast()
 	}
 }
 
 func (w *Watcher) deletePod(obj interface{}) {
+	w.counters.countEvent("pod", "delete")
 	pod, ok := obj.(*corev1.Pod)
 	if !ok {
 		tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
@@ -349,7 +959,17 @@ func (w *Watcher) deletePod(obj interface{}) {
 		}
 	}
 	w.mu.Lock()
-	delete(w.pods, pod.Name)
+	key := podKey(pod.Namespace, pod.Name)
+	if existing, ok := w.pods[key]; ok {
+		w.trackPodWorkload(key, existing, nil)
+		w.trackPodController(key, nil)
+		w.trackPodNode(existing.NodeName, "")
+		w.trackPodAllocation(key, nil)
+		w.trackPodClaims(pod.Namespace, pod.Name, existing.VolumeClaims, nil)
+		w.markRollout(existing)
+		delete(w.pods, key)
+		w.recordDelta(model.Delta{Type: model.DeltaPodDelete, Pod: copyPod(existing)})
+	}
 	w.mu.Unlock()
 	w.broadcast()
 }
@@ -390,69 +1010,154 @@ func (w *Watcher) convertNode(n *corev1.Node) *model.Node {
 		}
 	}
 	if status != "NotReady" {
-		if n.Spec.Unschedulable {
-			status = "Cordoned"
+		status = schedulingStatus(n, status)
+	}
+
+	taints := make([]model.Taint, 0, len(n.Spec.Taints))
+	for _, t := range n.Spec.Taints {
+		timeAdded := ""
+		if t.TimeAdded != nil {
+			timeAdded = t.TimeAdded.Time.Format(time.RFC3339)
 		}
+		taints = append(taints, model.Taint{
+			Key:       t.Key,
+			Value:     t.Value,
+			Effect:    string(t.Effect),
+			TimeAdded: timeAdded,
+		})
+	}
+
+	// Conditions, without the heartbeat time which changes on every kubelet status update
+	conditions := make([]model.NodeCondition, 0, len(n.Status.Conditions))
+	for _, condition := range n.Status.Conditions {
+		conditions = append(conditions, model.NodeCondition{
+			Type:               string(condition.Type),
+			Status:             string(condition.Status),
+			Reason:             condition.Reason,
+			Message:            condition.Message,
+			LastTransitionTime: condition.LastTransitionTime.Time.Format(time.RFC3339),
+		})
 	}
 
 	return &model.Node{
+		Cluster:                 w.cluster,
 		Name:                    n.Name,
 		Status:                  status,
+		Conditions:              conditions,
+		Severity:                nodeSeverity(n.Status.Conditions),
+		Taints:                  taints,
 		Roles:                   roles,
 		Labels:                  n.Labels,
 		Capacity:                capacity,
 		Allocatable:             allocatable,
+		CapacityValues:          nodeResources(n.Status.Capacity),
+		AllocatableValues:       nodeResources(n.Status.Allocatable),
 		Version:                 n.Status.NodeInfo.KubeletVersion,
 		KernelVersion:           n.Status.NodeInfo.KernelVersion,
 		OSImage:                 n.Status.NodeInfo.OSImage,
 		ContainerRuntimeVersion: n.Status.NodeInfo.ContainerRuntimeVersion,
+		Zone:                    w.topologyValue(n, "zone", ""),
+		Region:                  w.topologyValue(n, "region", ""),
+		InstanceType:            w.topologyValue(n, "instance_type", ""),
+		Arch:                    w.topologyValue(n, "arch", n.Status.NodeInfo.Architecture),
+		OS:                      w.topologyValue(n, "os", n.Status.NodeInfo.OperatingSystem),
+		ProviderID:              w.topologyValue(n, "provider_id", n.Spec.ProviderID),
+		NodePool:                w.topologyValue(n, "node_pool", ""),
+		AttachedVolumes:         w.nodeVolumes(n.Name),
+		Allocation:              w.nodeAllocation(n.Name, n.Status.Allocatable.Pods().Value(), nil),
+	}
+}
+
+// Well-known taints used by autoscalers while removing nodes
+const (
+	taintToBeDeleted         = "ToBeDeletedByClusterAutoscaler"
+	taintDeletionCandidate   = "DeletionCandidateOfClusterAutoscaler"
+	taintKarpenterDisrupted  = "karpenter.sh/disrupted"
+	taintKarpenterDisrupting = "karpenter.sh/disruption"
+)
+
+// schedulingStatus derives the status of a ready node from cordoning and the taints
+// autoscalers set while removing it. A node being drained takes precedence over a plain
+// cordon, which takes precedence over being a scale-down candidate.
+func schedulingStatus(n *corev1.Node, status string) string {
+	candidate := false
+	for _, t := range n.Spec.Taints {
+		switch t.Key {
+		case taintToBeDeleted, taintKarpenterDisrupted:
+			return "Draining"
+		case taintKarpenterDisrupting:
+			if t.Value == "disrupting" {
+				return "Draining"
+			}
+		case taintDeletionCandidate:
+			candidate = true
+		}
+	}
+	if n.Spec.Unschedulable {
+		return "Cordoned"
+	}
+	if candidate {
+		return "ScaleDownCandidate"
 	}
+	return status
+}
+
+// nodeSeverity derives how urgently a node needs attention from its conditions. A node
+// that is not ready or has no network is critical. Any other condition that is true,
+// such as MemoryPressure or a node-problem-detector condition like KernelDeadlock, is a
+// warning, since by convention only Ready is true when healthy.
+func nodeSeverity(conditions []corev1.NodeCondition) string {
+	severity := model.SeverityOK
+	ready := false
+	for _, condition := range conditions {
+		switch {
+		case condition.Type == corev1.NodeReady:
+			ready = condition.Status == corev1.ConditionTrue
+		case condition.Status != corev1.ConditionTrue:
+			// Condition not in effect
+		case condition.Type == corev1.NodeNetworkUnavailable:
+			return model.SeverityCritical
+		default:
+			severity = model.SeverityWarning
+		}
+	}
+	if !ready {
+		return model.SeverityCritical
+	}
+	return severity
 }
 
 func (w *Watcher) convertPod(p *corev1.Pod) *model.Pod {
 	restarts := 0
 	containers := []model.ContainerInfo{}
 
-	cpuReq := resource.NewQuantity(0, resource.DecimalSI)
-	cpuLim := resource.NewQuantity(0, resource.DecimalSI)
-	memReq := resource.NewQuantity(0, resource.BinarySI)
-	memLim := resource.NewQuantity(0, resource.BinarySI)
+	// Effective resources, as reserved by the scheduler
+	requests, limits := podRequirements(p)
+	cpuReq, cpuLim := requests.Cpu(), limits.Cpu()
+	memReq, memLim := requests.Memory(), limits.Memory()
 
-	// Process containers
 	for _, c := range p.Spec.Containers {
-		// Resources
-		if q := c.Resources.Requests.Cpu(); q != nil {
-			cpuReq.Add(*q)
-		}
-		if q := c.Resources.Limits.Cpu(); q != nil {
-			cpuLim.Add(*q)
-		}
-		if q := c.Resources.Requests.Memory(); q != nil {
-			memReq.Add(*q)
-		}
-		if q := c.Resources.Limits.Memory(); q != nil {
-			memLim.Add(*q)
-		}
+		info := convertContainer(c.Name, c.Image, c.ImagePullPolicy, findContainerStatus(p.Status.ContainerStatuses, c.Name))
+		info.Resources = containerResources(c.Resources)
+		restarts += info.Restarts
+		containers = append(containers, info)
 	}
 
-	for _, cs := range p.Status.ContainerStatuses {
-		restarts += int(cs.RestartCount)
-
-		state := "unknown"
-		if cs.State.Running != nil {
-			state = "running"
-		} else if cs.State.Waiting != nil {
-			state = "waiting"
-		} else if cs.State.Terminated != nil {
-			state = "terminated"
+	// Init containers, native sidecars keep running and count towards restarts like in kubectl
+	initContainers := []model.ContainerInfo{}
+	for _, c := range p.Spec.InitContainers {
+		info := convertContainer(c.Name, c.Image, c.ImagePullPolicy, findContainerStatus(p.Status.InitContainerStatuses, c.Name))
+		info.Resources = containerResources(c.Resources)
+		if c.RestartPolicy != nil && *c.RestartPolicy == corev1.ContainerRestartPolicyAlways {
+			info.Sidecar = true
+			restarts += info.Restarts
 		}
+		initContainers = append(initContainers, info)
+	}
 
-		containers = append(containers, model.ContainerInfo{
-			Name:     cs.Name,
-			State:    state,
-			Ready:    cs.Ready,
-			Restarts: int(cs.RestartCount),
-		})
+	ephemeralContainers := []model.ContainerInfo{}
+	for _, c := range p.Spec.EphemeralContainers {
+		ephemeralContainers = append(ephemeralContainers, convertContainer(c.Name, c.Image, c.ImagePullPolicy, findContainerStatus(p.Status.EphemeralContainerStatuses, c.Name)))
 	}
 
 	startTime := ""
@@ -460,55 +1165,13 @@ func (w *Watcher) convertPod(p *corev1.Pod) *model.Pod {
 		startTime = p.Status.StartTime.Time.Format(time.RFC3339)
 	}
 
-	// Calculate detailed status
-	status := string(p.Status.Phase)
-	if p.DeletionTimestamp != nil {
-		status = "Terminating"
-	} else {
-		// Check for init container failures/waiting
-		for _, statusInfo := range p.Status.InitContainerStatuses {
-			if statusInfo.State.Terminated != nil && statusInfo.State.Terminated.ExitCode != 0 {
-				status = "Init:Error"
-				break
-			}
-			if statusInfo.State.Waiting != nil && statusInfo.State.Waiting.Reason != "" {
-				status = "Init:" + statusInfo.State.Waiting.Reason
-				break
-			}
-		}
+	status := podReason(p)
 
-		// Check for container failures/waiting if init containers are fine
-		if status == string(p.Status.Phase) {
-			for _, statusInfo := range p.Status.ContainerStatuses {
-				if statusInfo.State.Waiting != nil && statusInfo.State.Waiting.Reason != "" {
-					status = statusInfo.State.Waiting.Reason
-					break
-				}
-				if statusInfo.State.Terminated != nil && statusInfo.State.Terminated.Reason != "" {
-					status = statusInfo.State.Terminated.Reason
-					break
-				}
-				if statusInfo.State.Terminated != nil && statusInfo.State.Terminated.Reason == "" {
-					if statusInfo.State.Terminated.Signal != 0 {
-						status = "Signal:" + string(statusInfo.State.Terminated.Signal)
-					} else {
-						status = "ExitCode:" + string(statusInfo.State.Terminated.ExitCode)
-					}
-					break
-				}
-			}
-		}
-	}
-
-	// Determine controller type from OwnerReferences
+	// Determine controller type from the top-level owner
+	workloadKind, workloadName := w.resolveWorkload(p)
 	controllerType := "Standalone"
-	if len(p.OwnerReferences) > 0 {
-		owner := p.OwnerReferences[0]
-		controllerType = owner.Kind
-		// Map ReplicaSet to Deployment if possible (most ReplicaSets are owned by Deployments)
-		if owner.Kind == "ReplicaSet" {
-			controllerType = "Deployment"
-		}
+	if workloadKind != "" {
+		controllerType = workloadKind
 	}
 	// Check for static pods (no owner and specific annotation)
 	if len(p.OwnerReferences) == 0 {
@@ -517,22 +1180,187 @@ func (w *Watcher) convertPod(p *corev1.Pod) *model.Pod {
 		}
 	}
 
-	return &model.Pod{
-		Name:           p.Name,
-		Namespace:      p.Namespace,
-		Status:         status,
-		NodeName:       p.Spec.NodeName,
-		Labels:         p.Labels,
-		IP:             p.Status.PodIP,
-		StartTime:      startTime,
-		Restarts:       restarts,
-		Containers:     containers,
-		ControllerType: controllerType,
+	pod := &model.Pod{
+		ID:                  string(p.UID),
+		Cluster:             w.cluster,
+		Name:                p.Name,
+		Namespace:           p.Namespace,
+		Status:              status,
+		Phase:               string(p.Status.Phase),
+		Health:              podHealth(p, status),
+		NodeName:            p.Spec.NodeName,
+		Labels:              p.Labels,
+		IP:                  p.Status.PodIP,
+		StartTime:           startTime,
+		Restarts:            restarts,
+		Containers:          containers,
+		InitContainers:      initContainers,
+		EphemeralContainers: ephemeralContainers,
+		ControllerType:      controllerType,
+		WorkloadKind:        workloadKind,
+		WorkloadName:        workloadName,
+		WorkloadRevision:    podRevision(p),
+		Services:            w.podServices(podKey(p.Namespace, p.Name)),
+		VolumeClaims:        w.podVolumeClaims(p),
+		QOSClass:            string(p.Status.QOSClass),
 		Resources: &model.PodResources{
-			CPURequested:    cpuReq.String(),
-			CPULimit:        cpuLim.String(),
-			MemoryRequested: memReq.String(),
-			MemoryLimit:     memLim.String(),
+			CPURequested:         cpuReq.String(),
+			CPULimit:             cpuLim.String(),
+			MemoryRequested:      memReq.String(),
+			MemoryLimit:          memLim.String(),
+			CPURequestedMilli:    cpuReq.MilliValue(),
+			CPULimitMilli:        cpuLim.MilliValue(),
+			MemoryRequestedBytes: memReq.Value(),
+			MemoryLimitBytes:     memLim.Value(),
+			Requests:             resourceStrings(requests),
+			Limits:               resourceStrings(limits),
 		},
 	}
+	pod.CurrentRevision = w.isCurrentRevision(pod)
+	return pod
+}
+
+// withContainerMetrics returns a copy of containers with the given usage attached and the
+// usage of the containers whose metrics changed
+func withContainerMetrics(containers []model.ContainerInfo, metrics map[string]model.Metrics) ([]model.ContainerInfo, map[string]model.Metrics) {
+	changed := make(map[string]model.Metrics)
+	updated := make([]model.ContainerInfo, len(containers))
+	copy(updated, containers)
+	for i := range updated {
+		m, ok := metrics[updated[i].Name]
+		if !ok {
+			continue
+		}
+		if updated[i].Metrics == nil || !updated[i].Metrics.Equals(m) {
+			changed[updated[i].Name] = m
+		}
+		updated[i].Metrics = &m
+	}
+	return updated, changed
+}
+
+// preserveContainerMetrics carries the last known usage over to freshly converted containers
+func preserveContainerMetrics(containers, existing []model.ContainerInfo) {
+	for i := range containers {
+		for _, e := range existing {
+			if e.Name == containers[i].Name && e.Metrics != nil {
+				containers[i].Metrics = e.Metrics
+				break
+			}
+		}
+	}
+}
+
+// containerResources returns the requests and limits of a single container
+func containerResources(r corev1.ResourceRequirements) *model.PodResources {
+	resources := &model.PodResources{}
+	if q, ok := r.Requests[corev1.ResourceCPU]; ok {
+		resources.CPURequested, resources.CPURequestedMilli = q.String(), q.MilliValue()
+	}
+	if q, ok := r.Limits[corev1.ResourceCPU]; ok {
+		resources.CPULimit, resources.CPULimitMilli = q.String(), q.MilliValue()
+	}
+	if q, ok := r.Requests[corev1.ResourceMemory]; ok {
+		resources.MemoryRequested, resources.MemoryRequestedBytes = q.String(), q.Value()
+	}
+	if q, ok := r.Limits[corev1.ResourceMemory]; ok {
+		resources.MemoryLimit, resources.MemoryLimitBytes = q.String(), q.Value()
+	}
+	resources.Requests = resourceStrings(r.Requests)
+	resources.Limits = resourceStrings(r.Limits)
+	return resources
+}
+
+// resourceStrings converts a resource list to display strings keyed by resource name, nil if it is empty
+func resourceStrings(list corev1.ResourceList) map[string]string {
+	if len(list) == 0 {
+		return nil
+	}
+	values := make(map[string]string, len(list))
+	for name, q := range list {
+		values[string(name)] = q.String()
+	}
+	return values
+}
+
+// convertMetrics converts a CPU and memory usage
+func convertMetrics(cpu, memory *resource.Quantity) *model.Metrics {
+	return &model.Metrics{
+		CPU:         cpu.String(),
+		Memory:      memory.String(),
+		CPUMilli:    cpu.MilliValue(),
+		MemoryBytes: memory.Value(),
+	}
+}
+
+// nodeResources returns the numeric values of a node resource list
+func nodeResources(list corev1.ResourceList) model.NodeResources {
+	return model.NodeResources{
+		CPUMilli:              list.Cpu().MilliValue(),
+		MemoryBytes:           list.Memory().Value(),
+		EphemeralStorageBytes: list.StorageEphemeral().Value(),
+		Pods:                  list.Pods().Value(),
+	}
+}
+
+// findContainerStatus returns the status of the named container, nil if it has none yet
+func findContainerStatus(statuses []corev1.ContainerStatus, name string) *corev1.ContainerStatus {
+	for i := range statuses {
+		if statuses[i].Name == name {
+			return &statuses[i]
+		}
+	}
+	return nil
+}
+
+// convertContainer converts the status of a container, cs is nil if the container has no status yet
+func convertContainer(name, image string, pullPolicy corev1.PullPolicy, cs *corev1.ContainerStatus) model.ContainerInfo {
+	info := model.ContainerInfo{
+		Name:            name,
+		State:           "unknown",
+		Image:           image,
+		ImagePullPolicy: string(pullPolicy),
+	}
+	if cs == nil {
+		return info
+	}
+
+	if cs.State.Running != nil {
+		info.State = "running"
+	} else if cs.State.Waiting != nil {
+		info.State = "waiting"
+		info.Reason = cs.State.Waiting.Reason
+		info.Message = cs.State.Waiting.Message
+	} else if cs.State.Terminated != nil {
+		info.State = "terminated"
+		info.Reason = cs.State.Terminated.Reason
+		info.Message = cs.State.Terminated.Message
+	}
+	if t := cs.LastTerminationState.Terminated; t != nil {
+		info.LastState = convertTermination(t)
+	}
+	info.OOMKilled = (cs.State.Terminated != nil && cs.State.Terminated.Reason == "OOMKilled") ||
+		(info.LastState != nil && info.LastState.Reason == "OOMKilled")
+	info.Ready = cs.Ready
+	info.Restarts = int(cs.RestartCount)
+	info.Started = cs.Started != nil && *cs.Started
+	info.ImageID = cs.ImageID
+	return info
+}
+
+// convertTermination converts a terminated container state
+func convertTermination(t *corev1.ContainerStateTerminated) *model.ContainerTermination {
+	termination := &model.ContainerTermination{
+		Reason:   t.Reason,
+		Message:  t.Message,
+		ExitCode: t.ExitCode,
+		Signal:   t.Signal,
+	}
+	if !t.StartedAt.IsZero() {
+		termination.StartedAt = t.StartedAt.Time.Format(time.RFC3339)
+	}
+	if !t.FinishedAt.IsZero() {
+		termination.FinishedAt = t.FinishedAt.Time.Format(time.RFC3339)
+	}
+	return termination
 }
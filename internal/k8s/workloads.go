@@ -0,0 +1,191 @@
+package k8s
+
+import (
+	"strings"
+	"time"
+
+	"github.com/pettersolberg88/kube-ops-view-ng/internal/model"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// workloadHandlers returns the event handlers that keep the workloads of a kind cached.
+// convert returns nil for objects that are not shown as workloads of their own.
+func (w *Watcher) workloadHandlers(kind string, convert func(obj interface{}) *model.Workload) cache.ResourceEventHandlerFuncs {
+	resource := strings.ToLower(kind)
+	return cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			w.counters.countEvent(resource, "add")
+			w.upsertWorkload(kind, obj, convert(obj))
+		},
+		UpdateFunc: func(old, new interface{}) {
+			w.counters.countEvent(resource, "update")
+			w.upsertWorkload(kind, new, convert(new))
+		},
+		DeleteFunc: func(obj interface{}) {
+			w.counters.countEvent(resource, "delete")
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			w.upsertWorkload(kind, obj, nil)
+		},
+	}
+}
+
+// upsertWorkload stores a converted workload, or removes it if workload is nil
+func (w *Watcher) upsertWorkload(kind string, obj interface{}, workload *model.Workload) {
+	o, err := meta.Accessor(obj)
+	if err != nil {
+		return
+	}
+	key := workloadKey(kind, o.GetNamespace(), o.GetName())
+
+	w.mu.Lock()
+	existing, exists := w.workloads[key]
+	toBroadcast := false
+	if isRolloutKind(kind) {
+		w.staleRollouts[key] = true
+	}
+	if workload == nil {
+		if exists {
+			delete(w.workloads, key)
+			w.recordDelta(model.Delta{Type: model.DeltaWorkloadDelete, Workload: existing})
+			toBroadcast = true
+		}
+	} else {
+		workload.Cluster = w.cluster
+		if !exists || !workload.Equals(existing) {
+			w.workloads[key] = workload
+			w.recordDelta(model.Delta{Type: model.DeltaWorkloadUpsert, Workload: workload})
+			toBroadcast = true
+		}
+	}
+	w.mu.Unlock()
+	if toBroadcast {
+		w.broadcast()
+	}
+}
+
+// newWorkload returns a workload with the common fields set
+func newWorkload(kind string, o metav1.Object, selector *metav1.LabelSelector) *model.Workload {
+	workload := &model.Workload{
+		Kind:       kind,
+		Namespace:  o.GetNamespace(),
+		Name:       o.GetName(),
+		Conditions: []model.WorkloadCondition{},
+	}
+	if selector != nil {
+		workload.Selector = metav1.FormatLabelSelector(selector)
+	}
+	return workload
+}
+
+// workloadCondition converts the fields shared by all workload condition types
+func workloadCondition(conditionType string, status corev1.ConditionStatus, reason, message string, lastTransition metav1.Time) model.WorkloadCondition {
+	return model.WorkloadCondition{
+		Type:               conditionType,
+		Status:             string(status),
+		Reason:             reason,
+		Message:            message,
+		LastTransitionTime: lastTransition.Time.Format(time.RFC3339),
+	}
+}
+
+// desiredReplicas returns the replicas of a spec, which default to one
+func desiredReplicas(replicas *int32) int {
+	if replicas == nil {
+		return 1
+	}
+	return int(*replicas)
+}
+
+func convertDeployment(obj interface{}) *model.Workload {
+	d, ok := obj.(*appsv1.Deployment)
+	if !ok {
+		return nil
+	}
+	workload := newWorkload("Deployment", d, d.Spec.Selector)
+	workload.Replicas = desiredReplicas(d.Spec.Replicas)
+	workload.ReadyReplicas = int(d.Status.ReadyReplicas)
+	workload.UpdatedReplicas = int(d.Status.UpdatedReplicas)
+	workload.AvailableReplicas = int(d.Status.AvailableReplicas)
+	for _, c := range d.Status.Conditions {
+		workload.Conditions = append(workload.Conditions, workloadCondition(string(c.Type), c.Status, c.Reason, c.Message, c.LastTransitionTime))
+	}
+	return workload
+}
+
+func convertStatefulSet(obj interface{}) *model.Workload {
+	s, ok := obj.(*appsv1.StatefulSet)
+	if !ok {
+		return nil
+	}
+	workload := newWorkload("StatefulSet", s, s.Spec.Selector)
+	workload.Replicas = desiredReplicas(s.Spec.Replicas)
+	workload.ReadyReplicas = int(s.Status.ReadyReplicas)
+	workload.UpdatedReplicas = int(s.Status.UpdatedReplicas)
+	workload.AvailableReplicas = int(s.Status.AvailableReplicas)
+	for _, c := range s.Status.Conditions {
+		workload.Conditions = append(workload.Conditions, workloadCondition(string(c.Type), c.Status, c.Reason, c.Message, c.LastTransitionTime))
+	}
+	return workload
+}
+
+func convertDaemonSet(obj interface{}) *model.Workload {
+	d, ok := obj.(*appsv1.DaemonSet)
+	if !ok {
+		return nil
+	}
+	workload := newWorkload("DaemonSet", d, d.Spec.Selector)
+	workload.Replicas = int(d.Status.DesiredNumberScheduled)
+	workload.ReadyReplicas = int(d.Status.NumberReady)
+	workload.UpdatedReplicas = int(d.Status.UpdatedNumberScheduled)
+	workload.AvailableReplicas = int(d.Status.NumberAvailable)
+	for _, c := range d.Status.Conditions {
+		workload.Conditions = append(workload.Conditions, workloadCondition(string(c.Type), c.Status, c.Reason, c.Message, c.LastTransitionTime))
+	}
+	return workload
+}
+
+// convertReplicaSet converts a bare ReplicaSet. ReplicaSets managed by a Deployment or
+// another controller are represented by that controller.
+func convertReplicaSet(obj interface{}) *model.Workload {
+	r, ok := obj.(*appsv1.ReplicaSet)
+	if !ok || metav1.GetControllerOfNoCopy(r) != nil {
+		return nil
+	}
+	workload := newWorkload("ReplicaSet", r, r.Spec.Selector)
+	workload.Replicas = desiredReplicas(r.Spec.Replicas)
+	workload.ReadyReplicas = int(r.Status.ReadyReplicas)
+	workload.AvailableReplicas = int(r.Status.AvailableReplicas)
+	for _, c := range r.Status.Conditions {
+		workload.Conditions = append(workload.Conditions, workloadCondition(string(c.Type), c.Status, c.Reason, c.Message, c.LastTransitionTime))
+	}
+	return workload
+}
+
+func convertJob(obj interface{}) *model.Workload {
+	j, ok := obj.(*batchv1.Job)
+	if !ok {
+		return nil
+	}
+	workload := newWorkload("Job", j, j.Spec.Selector)
+	// A work queue Job has no completions and runs its parallelism
+	if j.Spec.Completions != nil {
+		workload.Replicas = int(*j.Spec.Completions)
+	} else {
+		workload.Replicas = desiredReplicas(j.Spec.Parallelism)
+	}
+	if j.Status.Ready != nil {
+		workload.ReadyReplicas = int(*j.Status.Ready)
+	}
+	workload.AvailableReplicas = int(j.Status.Succeeded)
+	for _, c := range j.Status.Conditions {
+		workload.Conditions = append(workload.Conditions, workloadCondition(string(c.Type), c.Status, c.Reason, c.Message, c.LastTransitionTime))
+	}
+	return workload
+}
@@ -1,76 +1,409 @@
 package model
 
-// Metrics represents resource usage
+// Metrics represents resource usage. The strings are for display, the numeric fields hold
+// the same values in millicores and bytes.
 type Metrics struct {
-	CPU    string `json:"cpu"`
-	Memory string `json:"memory"`
+	CPU         string `json:"cpu"`
+	Memory      string `json:"memory"`
+	CPUMilli    int64  `json:"cpu_milli"`
+	MemoryBytes int64  `json:"memory_bytes"`
+}
+
+// Node severities, derived from the node conditions
+const (
+	SeverityOK       = "ok"
+	SeverityWarning  = "warning"
+	SeverityCritical = "critical"
+)
+
+// Pod health categories, derived from the phase and status
+const (
+	HealthHealthy     = "healthy"
+	HealthProgressing = "progressing"
+	HealthWarning     = "warning"
+	HealthFailed      = "failed"
+)
+
+// NodeCondition represents a node condition such as MemoryPressure
+type NodeCondition struct {
+	Type               string `json:"type"`
+	Status             string `json:"status"`
+	Reason             string `json:"reason"`
+	Message            string `json:"message"`
+	LastTransitionTime string `json:"last_transition_time"`
+}
+
+// Taint represents a node taint
+type Taint struct {
+	Key       string `json:"key"`
+	Value     string `json:"value"`
+	Effect    string `json:"effect"`
+	TimeAdded string `json:"time_added,omitempty"`
 }
 
 // Node represents a Kubernetes node
 type Node struct {
+	Cluster                 string            `json:"cluster"`
 	Name                    string            `json:"name"`
 	Status                  string            `json:"status"`
+	Conditions              []NodeCondition   `json:"conditions"`
+	Severity                string            `json:"severity"`
+	Taints                  []Taint           `json:"taints"`
 	Roles                   []string          `json:"roles"`
 	Labels                  map[string]string `json:"labels"`
 	Capacity                map[string]string `json:"capacity"`
 	Allocatable             map[string]string `json:"allocatable"`
+	CapacityValues          NodeResources     `json:"capacity_values"`
+	AllocatableValues       NodeResources     `json:"allocatable_values"`
 	Metrics                 *Metrics          `json:"metrics,omitempty"`
 	Version                 string            `json:"version"`
 	KernelVersion           string            `json:"kernel_version"`
 	OSImage                 string            `json:"os_image"`
 	ContainerRuntimeVersion string            `json:"container_runtime_version"`
+	Zone                    string            `json:"zone"`
+	Region                  string            `json:"region"`
+	InstanceType            string            `json:"instance_type"`
+	Arch                    string            `json:"arch"`
+	OS                      string            `json:"os"`
+	ProviderID              string            `json:"provider_id"`
+	NodePool                string            `json:"node_pool"` // Node group, node pool or instance group of the provisioner
+	AttachedVolumes         []VolumeDriver    `json:"attached_volumes,omitempty"`
+	Allocation              *NodeAllocation   `json:"allocation,omitempty"`
+	RecentEvents            []Event           `json:"recent_events,omitempty"`
+}
+
+// NodeResources holds the numeric capacity or allocatable resources of a node
+type NodeResources struct {
+	CPUMilli              int64 `json:"cpu_milli"`
+	MemoryBytes           int64 `json:"memory_bytes"`
+	EphemeralStorageBytes int64 `json:"ephemeral_storage_bytes"`
+	Pods                  int64 `json:"pods"`
+}
+
+// NodeAllocation sums the requests and limits of the non-terminal pods scheduled on a node
+type NodeAllocation struct {
+	Pods                           int               `json:"pods"`
+	PodsAllocatable                int64             `json:"pods_allocatable"`
+	CPURequestedMilli              int64             `json:"cpu_requested_milli"`
+	CPULimitMilli                  int64             `json:"cpu_limit_milli"`
+	MemoryRequestedBytes           int64             `json:"memory_requested_bytes"`
+	MemoryLimitBytes               int64             `json:"memory_limit_bytes"`
+	EphemeralStorageRequestedBytes int64             `json:"ephemeral_storage_requested_bytes"`
+	EphemeralStorageLimitBytes     int64             `json:"ephemeral_storage_limit_bytes"`
+	ExtendedRequested              map[string]int64  `json:"extended_requested,omitempty"` // Other resources such as GPUs and hugepages, by resource name
+	ExtendedLimits                 map[string]int64  `json:"extended_limits,omitempty"`
+	Requests                       map[string]string `json:"requests,omitempty"` // Every resource by name, like the allocatable of the node
+	Limits                         map[string]string `json:"limits,omitempty"`
+	CPUUsageMilli                  int64             `json:"cpu_usage_milli"` // From metrics, zero when unavailable
+	MemoryUsageBytes               int64             `json:"memory_usage_bytes"`
+}
+
+// VolumeDriver is the volumes a CSI driver has attached to a node and its attach limit
+type VolumeDriver struct {
+	Driver   string `json:"driver"`
+	Attached int    `json:"attached"` // VolumeAttachments, including those still attaching
+	Limit    int    `json:"limit"`    // Reported by CSINode, 0 if the driver has no limit
+}
+
+// Event represents a Kubernetes event about a pod or node
+type Event struct {
+	Cluster   string `json:"cluster"`
+	Namespace string `json:"namespace"`
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Type      string `json:"type"`
+	Reason    string `json:"reason"`
+	Message   string `json:"message"`
+	Source    string `json:"source"`
+	Count     int    `json:"count"`
+	FirstSeen string `json:"first_seen"`
+	LastSeen  string `json:"last_seen"`
 }
 
 // ContainerInfo represents detailed container information
 type ContainerInfo struct {
-	Name     string `json:"name"`
-	State    string `json:"state"`
-	Ready    bool   `json:"ready"`
-	Restarts int    `json:"restarts"`
+	Name            string                `json:"name"`
+	State           string                `json:"state"`
+	Ready           bool                  `json:"ready"`
+	Restarts        int                   `json:"restarts"`
+	Started         bool                  `json:"started"`
+	Sidecar         bool                  `json:"sidecar,omitempty"` // Init container with restartPolicy Always
+	Image           string                `json:"image"`
+	ImageID         string                `json:"image_id"` // Resolved digest reported by the runtime, empty until pulled
+	ImagePullPolicy string                `json:"image_pull_policy"`
+	Reason          string                `json:"reason,omitempty"` // Reason of the current waiting or terminated state
+	Message         string                `json:"message,omitempty"`
+	LastState       *ContainerTermination `json:"last_state,omitempty"`
+	OOMKilled       bool                  `json:"oom_killed,omitempty"` // Current or last termination was OOMKilled
+	Metrics         *Metrics              `json:"metrics,omitempty"`
+	Resources       *PodResources         `json:"resources,omitempty"` // Requests and limits of this container
+}
+
+// ContainerTermination describes how a container terminated
+type ContainerTermination struct {
+	Reason     string `json:"reason"`
+	Message    string `json:"message,omitempty"`
+	ExitCode   int32  `json:"exit_code"`
+	Signal     int32  `json:"signal,omitempty"`
+	StartedAt  string `json:"started_at,omitempty"`
+	FinishedAt string `json:"finished_at,omitempty"`
+}
+
+// Image is a container image digest and the containers running it
+type Image struct {
+	Cluster    string           `json:"cluster"`
+	Image      string           `json:"image"`
+	ImageID    string           `json:"image_id"`
+	Nodes      []string         `json:"nodes"`
+	Containers []ImageContainer `json:"containers"`
+}
+
+// ImageContainer is a container running an image
+type ImageContainer struct {
+	Namespace string `json:"namespace"`
+	Pod       string `json:"pod"`
+	Container string `json:"container"`
+	NodeName  string `json:"node_name"`
 }
 
 // PodResources represents aggregated resource requests and limits
 type PodResources struct {
-	CPURequested    string `json:"cpu_requested"`
-	CPULimit        string `json:"cpu_limit"`
-	MemoryRequested string `json:"memory_requested"`
-	MemoryLimit     string `json:"memory_limit"`
+	CPURequested         string `json:"cpu_requested"`
+	CPULimit             string `json:"cpu_limit"`
+	MemoryRequested      string `json:"memory_requested"`
+	MemoryLimit          string `json:"memory_limit"`
+	CPURequestedMilli    int64  `json:"cpu_requested_milli"`
+	CPULimitMilli        int64  `json:"cpu_limit_milli"`
+	MemoryRequestedBytes int64  `json:"memory_requested_bytes"`
+	MemoryLimitBytes     int64  `json:"memory_limit_bytes"`
+	// Every requested and limited resource by name, including extended resources such as
+	// nvidia.com/gpu, hugepages and ephemeral-storage, like the capacity of a node
+	Requests map[string]string `json:"requests,omitempty"`
+	Limits   map[string]string `json:"limits,omitempty"`
 }
 
 // Pod represents a Kubernetes pod
 type Pod struct {
-	Name           string            `json:"name"`
-	Namespace      string            `json:"namespace"`
-	Status         string            `json:"status"`
-	NodeName       string            `json:"node_name"`
-	Labels         map[string]string `json:"labels"`
-	Metrics        *Metrics          `json:"metrics,omitempty"`
-	IP             string            `json:"ip"`
-	StartTime      string            `json:"start_time"`
-	Restarts       int               `json:"restarts"`
-	Containers     []ContainerInfo   `json:"containers"`
-	Resources      *PodResources     `json:"resources"`
-	ControllerType string            `json:"controller_type"`
+	ID                  string            `json:"id"`
+	Cluster             string            `json:"cluster"`
+	Name                string            `json:"name"`
+	Namespace           string            `json:"namespace"`
+	Status              string            `json:"status"` // As shown by kubectl get pods, e.g. Completed or Init:0/2
+	Phase               string            `json:"phase"`
+	Health              string            `json:"health"`
+	NodeName            string            `json:"node_name"`
+	Labels              map[string]string `json:"labels"`
+	Metrics             *Metrics          `json:"metrics,omitempty"`
+	IP                  string            `json:"ip"`
+	StartTime           string            `json:"start_time"`
+	Restarts            int               `json:"restarts"`
+	Containers          []ContainerInfo   `json:"containers"`
+	InitContainers      []ContainerInfo   `json:"init_containers"`
+	EphemeralContainers []ContainerInfo   `json:"ephemeral_containers"`
+	Resources           *PodResources     `json:"resources"` // Effective requests and limits, as reserved by the scheduler
+	QOSClass            string            `json:"qos_class"` // Guaranteed, Burstable or BestEffort
+	ControllerType      string            `json:"controller_type"`
+	WorkloadKind        string            `json:"workload_kind,omitempty"` // Top-level owner, e.g. Deployment or CronJob
+	WorkloadName        string            `json:"workload_name,omitempty"`
+	WorkloadRevision    string            `json:"workload_revision,omitempty"` // pod-template-hash or controller-revision-hash
+	CurrentRevision     bool              `json:"current_revision"`            // false if the current revision is unknown
+	Services            []PodService      `json:"services,omitempty"`
+	VolumeClaims        []VolumeClaim     `json:"volume_claims,omitempty"`
+	RecentEvents        []Event           `json:"recent_events,omitempty"`
+}
+
+// Workload represents a controller of pods and its desired and observed replicas. For
+// DaemonSets the replicas are the scheduled daemon pods. For Jobs the desired replicas
+// are the completions, or the parallelism of a work queue Job, and available replicas
+// are the succeeded pods. Jobs and bare ReplicaSets have no revisions, so their updated
+// replicas are not set.
+type Workload struct {
+	Cluster           string              `json:"cluster"`
+	Kind              string              `json:"kind"`
+	Namespace         string              `json:"namespace"`
+	Name              string              `json:"name"`
+	Replicas          int                 `json:"replicas"`
+	ReadyReplicas     int                 `json:"ready_replicas"`
+	UpdatedReplicas   int                 `json:"updated_replicas"`
+	AvailableReplicas int                 `json:"available_replicas"`
+	Conditions        []WorkloadCondition `json:"conditions"`
+	Selector          string              `json:"selector"`
+}
+
+// WorkloadCondition represents a condition of a workload, e.g. Available or Complete
+type WorkloadCondition struct {
+	Type               string `json:"type"`
+	Status             string `json:"status"`
+	Reason             string `json:"reason"`
+	Message            string `json:"message"`
+	LastTransitionTime string `json:"last_transition_time"`
+}
+
+// Rollout is the progress of a Deployment or StatefulSet towards its current revision.
+// StalledSince is only set for Deployments that exceeded their progress deadline,
+// StatefulSets have none.
+type Rollout struct {
+	Cluster      string `json:"cluster"`
+	Kind         string `json:"kind"`
+	Namespace    string `json:"namespace"`
+	Name         string `json:"name"`
+	Revision     string `json:"revision"` // pod-template-hash or controller-revision-hash of the current revision
+	Desired      int    `json:"desired"`
+	Updated      int    `json:"updated"` // Pods of the current revision
+	UpdatedReady int    `json:"updated_ready"`
+	OldRemaining int    `json:"old_remaining"` // Pods of previous revisions
+	Complete     bool   `json:"complete"`
+	LastProgress string `json:"last_progress"`
+	StalledSince string `json:"stalled_since,omitempty"`
+}
+
+// Service represents a Kubernetes service and the state of its endpoints. Severity is
+// critical when a service with a selector has no ready endpoints and warning when some
+// endpoints are not ready.
+type Service struct {
+	Cluster     string            `json:"cluster"`
+	Namespace   string            `json:"namespace"`
+	Name        string            `json:"name"`
+	Type        string            `json:"type"`
+	ClusterIP   string            `json:"cluster_ip"`
+	Selector    map[string]string `json:"selector,omitempty"`
+	Ready       int               `json:"ready"`
+	NotReady    int               `json:"not_ready"`
+	Terminating int               `json:"terminating"`
+	Severity    string            `json:"severity"`
+}
+
+// VolumeClaim is a persistent volume claim mounted by a pod. Capacity is the provisioned
+// size once bound and the requested size before. Only the name is set while the claim
+// is not known.
+type VolumeClaim struct {
+	Volume       string   `json:"volume"` // Name of the pod volume
+	Name         string   `json:"name"`
+	StorageClass string   `json:"storage_class"`
+	Capacity     string   `json:"capacity"`
+	Phase        string   `json:"phase"`
+	AccessModes  []string `json:"access_modes"`
+}
+
+// PodService is a service a pod is an endpoint of
+type PodService struct {
+	Name        string `json:"name"`
+	Ready       bool   `json:"ready"`
+	Terminating bool   `json:"terminating,omitempty"`
 }
 
 // ClusterState represents the current state of the cluster
 type ClusterState struct {
-	Nodes []Node `json:"nodes"`
-	Pods  []Pod  `json:"pods"`
+	Cluster   string        `json:"cluster,omitempty"`
+	Revision  uint64        `json:"revision"`
+	Clusters  []ClusterInfo `json:"clusters,omitempty"`
+	Nodes     []Node        `json:"nodes"`
+	Pods      []Pod         `json:"pods"`
+	Workloads []Workload    `json:"workloads"`
+	Rollouts  []Rollout     `json:"rollouts"`
+	Services  []Service     `json:"services"`
+}
+
+// Cluster connection states
+const (
+	ClusterSyncing  = "Syncing"
+	ClusterReady    = "Ready"
+	ClusterDegraded = "Degraded"
+)
+
+// ClusterInfo represents the connection state of a watched cluster
+type ClusterInfo struct {
+	Name        string `json:"name"`
+	Status      string `json:"status"`
+	Error       string `json:"error,omitempty"`
+	Revision    uint64 `json:"revision"`
+	HistoryFrom string `json:"history_from,omitempty"` // Earliest time /api/history can return
+	Nodes       int    `json:"nodes"`
+	Pods        int    `json:"pods"`
+}
+
+// HistoryRange is the cluster state at the start of a time range followed by the changes within it
+type HistoryRange struct {
+	From   string       `json:"from"`
+	To     string       `json:"to"`
+	State  ClusterState `json:"state"`
+	Deltas []Delta      `json:"deltas"`
+}
+
+// Delta event types, used as the SSE event name in delta mode
+const (
+	DeltaPodUpsert      = "pod-upsert"
+	DeltaPodDelete      = "pod-delete"
+	DeltaNodeUpsert     = "node-upsert"
+	DeltaNodeDelete     = "node-delete"
+	DeltaWorkloadUpsert = "workload-upsert"
+	DeltaWorkloadDelete = "workload-delete"
+	DeltaRolloutUpsert  = "rollout-upsert"
+	DeltaRolloutDelete  = "rollout-delete"
+	DeltaServiceUpsert  = "service-upsert"
+	DeltaServiceDelete  = "service-delete"
+	DeltaMetrics        = "metrics"
+)
+
+// Delta represents a single incremental change to the cluster state
+type Delta struct {
+	Type     string        `json:"type"`
+	Cluster  string        `json:"cluster"`
+	Revision uint64        `json:"revision"`
+	Time     string        `json:"time"`
+	Pod      *Pod          `json:"pod,omitempty"`
+	Node     *Node         `json:"node,omitempty"`
+	Workload *Workload     `json:"workload,omitempty"`
+	Rollout  *Rollout      `json:"rollout,omitempty"`
+	Service  *Service      `json:"service,omitempty"`
+	Metrics  *MetricsDelta `json:"metrics,omitempty"`
+}
+
+// MetricsDelta carries the metrics that changed in a poll, pods are keyed by namespace/name
+// and containers by namespace/name and then container name
+type MetricsDelta struct {
+	Nodes      map[string]Metrics            `json:"nodes,omitempty"`
+	Pods       map[string]Metrics            `json:"pods,omitempty"`
+	Containers map[string]map[string]Metrics `json:"containers,omitempty"`
 }
 
 func (p PodResources) Equals(other PodResources) bool {
-	return p.CPURequested == other.CPURequested && p.CPULimit == other.CPULimit && p.MemoryRequested == other.MemoryRequested && p.MemoryLimit == other.MemoryLimit
+	if p.CPURequested != other.CPURequested || p.CPULimit != other.CPULimit || p.MemoryRequested != other.MemoryRequested || p.MemoryLimit != other.MemoryLimit {
+		return false
+	}
+	if p.CPURequestedMilli != other.CPURequestedMilli || p.CPULimitMilli != other.CPULimitMilli ||
+		p.MemoryRequestedBytes != other.MemoryRequestedBytes || p.MemoryLimitBytes != other.MemoryLimitBytes {
+		return false
+	}
+	return resourcesEqual(p.Requests, other.Requests) && resourcesEqual(p.Limits, other.Limits)
+}
+
+func resourcesEqual(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if other, ok := b[k]; !ok || other != v {
+			return false
+		}
+	}
+	return true
 }
 
 func (p Pod) Equals(other *Pod) bool {
+	if p.ID != other.ID {
+		return false
+	}
+	if p.Cluster != other.Cluster {
+		return false
+	}
 	if p.Name != other.Name {
 		return false
 	}
 	if p.Namespace != other.Namespace {
 		return false
 	}
-	if p.Status != other.Status {
+	if p.Status != other.Status || p.Phase != other.Phase || p.Health != other.Health {
 		return false
 	}
 	if p.NodeName != other.NodeName {
@@ -98,36 +431,251 @@ func (p Pod) Equals(other *Pod) bool {
 	if p.Restarts != other.Restarts {
 		return false
 	}
-	if len(p.Containers) != len(other.Containers) {
+	if !containersEqual(p.Containers, other.Containers) {
 		return false
 	}
-	for i := range p.Containers {
-		if p.Containers[i] != other.Containers[i] {
-			return false
-		}
+	if !containersEqual(p.InitContainers, other.InitContainers) {
+		return false
+	}
+	if !containersEqual(p.EphemeralContainers, other.EphemeralContainers) {
+		return false
 	}
 	if p.Resources != nil && other.Resources != nil {
 		if !p.Resources.Equals(*other.Resources) {
 			return false
 		}
 	}
-	if p.ControllerType != other.ControllerType {
+	if p.ControllerType != other.ControllerType || p.WorkloadKind != other.WorkloadKind || p.WorkloadName != other.WorkloadName {
+		return false
+	}
+	if p.WorkloadRevision != other.WorkloadRevision || p.CurrentRevision != other.CurrentRevision {
+		return false
+	}
+	if !podServicesEqual(p.Services, other.Services) {
+		return false
+	}
+	if p.QOSClass != other.QOSClass {
+		return false
+	}
+	if !volumeClaimsEqual(p.VolumeClaims, other.VolumeClaims) {
+		return false
+	}
+	if !eventsEqual(p.RecentEvents, other.RecentEvents) {
+		return false
+	}
+	return true
+}
+
+func (c ContainerInfo) Equals(other ContainerInfo) bool {
+	if c.Name != other.Name || c.State != other.State || c.Ready != other.Ready || c.Restarts != other.Restarts {
+		return false
+	}
+	if c.Started != other.Started || c.Sidecar != other.Sidecar {
+		return false
+	}
+	if c.Image != other.Image || c.ImageID != other.ImageID || c.ImagePullPolicy != other.ImagePullPolicy {
+		return false
+	}
+	if c.Reason != other.Reason || c.Message != other.Message || c.OOMKilled != other.OOMKilled {
+		return false
+	}
+	if (c.LastState == nil) != (other.LastState == nil) {
+		return false
+	}
+	if c.LastState != nil && *c.LastState != *other.LastState {
+		return false
+	}
+	if c.Metrics != nil && other.Metrics != nil {
+		if !c.Metrics.Equals(*other.Metrics) {
+			return false
+		}
+	}
+	if (c.Resources == nil) != (other.Resources == nil) {
+		return false
+	}
+	if c.Resources != nil && !c.Resources.Equals(*other.Resources) {
 		return false
 	}
 	return true
 }
 
+func containersEqual(a, b []ContainerInfo) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !a[i].Equals(b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func eventsEqual(a, b []Event) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func (w Workload) Equals(other *Workload) bool {
+	if w.Cluster != other.Cluster || w.Kind != other.Kind || w.Namespace != other.Namespace || w.Name != other.Name {
+		return false
+	}
+	if w.Replicas != other.Replicas || w.ReadyReplicas != other.ReadyReplicas {
+		return false
+	}
+	if w.UpdatedReplicas != other.UpdatedReplicas || w.AvailableReplicas != other.AvailableReplicas {
+		return false
+	}
+	if len(w.Conditions) != len(other.Conditions) {
+		return false
+	}
+	for i := range w.Conditions {
+		if w.Conditions[i] != other.Conditions[i] {
+			return false
+		}
+	}
+	return w.Selector == other.Selector
+}
+
+func (s Service) Equals(other *Service) bool {
+	if s.Cluster != other.Cluster || s.Namespace != other.Namespace || s.Name != other.Name {
+		return false
+	}
+	if s.Type != other.Type || s.ClusterIP != other.ClusterIP || s.Severity != other.Severity {
+		return false
+	}
+	if s.Ready != other.Ready || s.NotReady != other.NotReady || s.Terminating != other.Terminating {
+		return false
+	}
+	if len(s.Selector) != len(other.Selector) {
+		return false
+	}
+	for k, v := range s.Selector {
+		if other.Selector[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+func podServicesEqual(a, b []PodService) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func volumeClaimsEqual(a, b []VolumeClaim) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Volume != b[i].Volume || a[i].Name != b[i].Name || a[i].StorageClass != b[i].StorageClass {
+			return false
+		}
+		if a[i].Capacity != b[i].Capacity || a[i].Phase != b[i].Phase || len(a[i].AccessModes) != len(b[i].AccessModes) {
+			return false
+		}
+		for j := range a[i].AccessModes {
+			if a[i].AccessModes[j] != b[i].AccessModes[j] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func volumeDriversEqual(a, b []VolumeDriver) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func (a NodeAllocation) Equals(other NodeAllocation) bool {
+	if a.Pods != other.Pods || a.PodsAllocatable != other.PodsAllocatable {
+		return false
+	}
+	if a.CPURequestedMilli != other.CPURequestedMilli || a.CPULimitMilli != other.CPULimitMilli {
+		return false
+	}
+	if a.MemoryRequestedBytes != other.MemoryRequestedBytes || a.MemoryLimitBytes != other.MemoryLimitBytes {
+		return false
+	}
+	if a.EphemeralStorageRequestedBytes != other.EphemeralStorageRequestedBytes || a.EphemeralStorageLimitBytes != other.EphemeralStorageLimitBytes {
+		return false
+	}
+	if a.CPUUsageMilli != other.CPUUsageMilli || a.MemoryUsageBytes != other.MemoryUsageBytes {
+		return false
+	}
+	if !quantitiesEqual(a.ExtendedRequested, other.ExtendedRequested) || !quantitiesEqual(a.ExtendedLimits, other.ExtendedLimits) {
+		return false
+	}
+	return resourcesEqual(a.Requests, other.Requests) && resourcesEqual(a.Limits, other.Limits)
+}
+
+func quantitiesEqual(a, b map[string]int64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if other, ok := b[k]; !ok || other != v {
+			return false
+		}
+	}
+	return true
+}
+
 func (m Metrics) Equals(other Metrics) bool {
-	return m.CPU == other.CPU && m.Memory == other.Memory
+	return m.CPU == other.CPU && m.Memory == other.Memory && m.CPUMilli == other.CPUMilli && m.MemoryBytes == other.MemoryBytes
 }
 
 func (n Node) Equals(other *Node) bool {
+	if n.Cluster != other.Cluster {
+		return false
+	}
 	if n.Name != other.Name {
 		return false
 	}
 	if n.Status != other.Status {
 		return false
 	}
+	if len(n.Conditions) != len(other.Conditions) {
+		return false
+	}
+	for i := range n.Conditions {
+		if n.Conditions[i] != other.Conditions[i] {
+			return false
+		}
+	}
+	if n.Severity != other.Severity {
+		return false
+	}
+	if len(n.Taints) != len(other.Taints) {
+		return false
+	}
+	for i := range n.Taints {
+		if n.Taints[i] != other.Taints[i] {
+			return false
+		}
+	}
 	if len(n.Roles) != len(other.Roles) {
 		return false
 	}
@@ -160,6 +708,9 @@ func (n Node) Equals(other *Node) bool {
 			return false
 		}
 	}
+	if n.CapacityValues != other.CapacityValues || n.AllocatableValues != other.AllocatableValues {
+		return false
+	}
 	if n.Metrics != nil && other.Metrics != nil {
 		if !n.Metrics.Equals(*other.Metrics) {
 			return false
@@ -177,5 +728,23 @@ func (n Node) Equals(other *Node) bool {
 	if n.ContainerRuntimeVersion != other.ContainerRuntimeVersion {
 		return false
 	}
+	if n.Zone != other.Zone || n.Region != other.Region || n.InstanceType != other.InstanceType {
+		return false
+	}
+	if n.Arch != other.Arch || n.OS != other.OS || n.ProviderID != other.ProviderID || n.NodePool != other.NodePool {
+		return false
+	}
+	if !volumeDriversEqual(n.AttachedVolumes, other.AttachedVolumes) {
+		return false
+	}
+	if (n.Allocation == nil) != (other.Allocation == nil) {
+		return false
+	}
+	if n.Allocation != nil && !n.Allocation.Equals(*other.Allocation) {
+		return false
+	}
+	if !eventsEqual(n.RecentEvents, other.RecentEvents) {
+		return false
+	}
 	return true
 }
@@ -0,0 +1,224 @@
+package server
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/pettersolberg88/kube-ops-view-ng/internal/model"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// filter selects the pods and nodes sent to a client. It is parsed from the query
+// parameters namespace, labelSelector, node, status and controllerType, where all
+// but labelSelector accept a comma separated list of values.
+type filter struct {
+	namespaces      map[string]bool
+	selector        labels.Selector
+	nodes           map[string]bool
+	statuses        map[string]bool
+	controllerTypes map[string]bool
+}
+
+// parseFilter returns the filter of the request, or nil if no filter parameter is set
+func parseFilter(query url.Values) (*filter, error) {
+	f := &filter{
+		namespaces:      parseSet(query.Get("namespace")),
+		nodes:           parseSet(query.Get("node")),
+		statuses:        parseSet(query.Get("status")),
+		controllerTypes: parseSet(query.Get("controllerType")),
+	}
+	if value := query.Get("labelSelector"); value != "" {
+		selector, err := labels.Parse(value)
+		if err != nil {
+			return nil, err
+		}
+		f.selector = selector
+	}
+	if f.namespaces == nil && f.nodes == nil && f.statuses == nil && f.controllerTypes == nil && f.selector == nil {
+		return nil, nil
+	}
+	return f, nil
+}
+
+// parseSet splits a comma separated list into a set, nil if the list is empty
+func parseSet(value string) map[string]bool {
+	var set map[string]bool
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			if set == nil {
+				set = make(map[string]bool)
+			}
+			set[item] = true
+		}
+	}
+	return set
+}
+
+// matchPod reports whether the pod passes the filter
+func (f *filter) matchPod(p *model.Pod) bool {
+	if f == nil {
+		return true
+	}
+	if f.namespaces != nil && !f.namespaces[p.Namespace] {
+		return false
+	}
+	if f.nodes != nil && !f.nodes[p.NodeName] {
+		return false
+	}
+	if f.statuses != nil && !f.statuses[p.Status] {
+		return false
+	}
+	if f.controllerTypes != nil && !f.controllerTypes[p.ControllerType] {
+		return false
+	}
+	if f.selector != nil && !f.selector.Matches(labels.Set(p.Labels)) {
+		return false
+	}
+	return true
+}
+
+// matchNode reports whether the node passes the filter, only the node parameter applies to nodes
+func (f *filter) matchNode(n *model.Node) bool {
+	if f == nil {
+		return true
+	}
+	return f.nodes == nil || f.nodes[n.Name]
+}
+
+// matchWorkload reports whether a workload or rollout of the given kind passes the filter,
+// only the namespace and controllerType parameters apply to workloads
+func (f *filter) matchWorkload(kind, namespace string) bool {
+	if f == nil {
+		return true
+	}
+	if f.namespaces != nil && !f.namespaces[namespace] {
+		return false
+	}
+	return f.controllerTypes == nil || f.controllerTypes[kind]
+}
+
+// matchService reports whether the service passes the filter, only the namespace parameter applies to services
+func (f *filter) matchService(s *model.Service) bool {
+	return f == nil || f.namespaces == nil || f.namespaces[s.Namespace]
+}
+
+// apply returns the state with all objects that do not pass the filter removed
+func (f *filter) apply(state model.ClusterState) model.ClusterState {
+	if f == nil {
+		return state
+	}
+
+	nodes := make([]model.Node, 0, len(state.Nodes))
+	for i := range state.Nodes {
+		if f.matchNode(&state.Nodes[i]) {
+			nodes = append(nodes, state.Nodes[i])
+		}
+	}
+	pods := make([]model.Pod, 0, len(state.Pods))
+	for i := range state.Pods {
+		if f.matchPod(&state.Pods[i]) {
+			pods = append(pods, state.Pods[i])
+		}
+	}
+	workloads := make([]model.Workload, 0, len(state.Workloads))
+	for _, wl := range state.Workloads {
+		if f.matchWorkload(wl.Kind, wl.Namespace) {
+			workloads = append(workloads, wl)
+		}
+	}
+	rollouts := make([]model.Rollout, 0, len(state.Rollouts))
+	for _, r := range state.Rollouts {
+		if f.matchWorkload(r.Kind, r.Namespace) {
+			rollouts = append(rollouts, r)
+		}
+	}
+	services := make([]model.Service, 0, len(state.Services))
+	for i := range state.Services {
+		if f.matchService(&state.Services[i]) {
+			services = append(services, state.Services[i])
+		}
+	}
+	state.Nodes = nodes
+	state.Pods = pods
+	state.Workloads = workloads
+	state.Rollouts = rollouts
+	state.Services = services
+	return state
+}
+
+// applyDelta filters a delta for a subscriber. visible holds the keys of the objects the
+// subscriber currently has, so that an object that stops matching is sent as a delete.
+// It returns false if the delta should not be sent at all.
+func (f *filter) applyDelta(d *model.Delta, visible map[string]bool) bool {
+	if f == nil {
+		return true
+	}
+
+	switch d.Type {
+	case model.DeltaPodUpsert, model.DeltaPodDelete:
+		key := d.Cluster + "/pod/" + d.Pod.Namespace + "/" + d.Pod.Name
+		if d.Type == model.DeltaPodUpsert && f.matchPod(d.Pod) {
+			visible[key] = true
+			return true
+		}
+		if !visible[key] {
+			return false
+		}
+		delete(visible, key)
+		d.Type = model.DeltaPodDelete
+		return true
+	case model.DeltaNodeUpsert, model.DeltaNodeDelete:
+		return f.matchNode(d.Node)
+	case model.DeltaWorkloadUpsert, model.DeltaWorkloadDelete:
+		return f.matchWorkload(d.Workload.Kind, d.Workload.Namespace)
+	case model.DeltaRolloutUpsert, model.DeltaRolloutDelete:
+		return f.matchWorkload(d.Rollout.Kind, d.Rollout.Namespace)
+	case model.DeltaServiceUpsert, model.DeltaServiceDelete:
+		return f.matchService(d.Service)
+	case model.DeltaMetrics:
+		metrics := &model.MetricsDelta{Pods: make(map[string]model.Metrics)}
+		for name, m := range d.Metrics.Nodes {
+			if f.nodes == nil || f.nodes[name] {
+				if metrics.Nodes == nil {
+					metrics.Nodes = make(map[string]model.Metrics)
+				}
+				metrics.Nodes[name] = m
+			}
+		}
+		for key, m := range d.Metrics.Pods {
+			if visible[d.Cluster+"/pod/"+key] {
+				metrics.Pods[key] = m
+			}
+		}
+		for key, containers := range d.Metrics.Containers {
+			if visible[d.Cluster+"/pod/"+key] {
+				if metrics.Containers == nil {
+					metrics.Containers = make(map[string]map[string]model.Metrics)
+				}
+				metrics.Containers[key] = containers
+			}
+		}
+		if len(metrics.Nodes) == 0 && len(metrics.Pods) == 0 && len(metrics.Containers) == 0 {
+			return false
+		}
+		d.Metrics = metrics
+		return true
+	}
+	return true
+}
+
+// track replaces the visible pods of a cluster with those of a filtered snapshot
+func (f *filter) track(state model.ClusterState, visible map[string]bool) {
+	if f == nil {
+		return
+	}
+	prefix := state.Cluster + "/pod/"
+	for key := range visible {
+		if strings.HasPrefix(key, prefix) {
+			delete(visible, key)
+		}
+	}
+	for _, p := range state.Pods {
+		visible[p.Cluster+"/pod/"+p.Namespace+"/"+p.Name] = true
+	}
+}
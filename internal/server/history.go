@@ -0,0 +1,95 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/pettersolberg88/kube-ops-view-ng/internal/model"
+)
+
+// parseTime parses a timestamp given as RFC 3339 or unix seconds
+func parseTime(value string) (time.Time, error) {
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(seconds, 0), nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid timestamp %q, expected RFC 3339 or unix seconds", value)
+	}
+	return t, nil
+}
+
+func (s *Server) handleHistory(w http.ResponseWriter, r *http.Request) {
+	src, _, status, err := s.resolve(r)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+	f, err := parseFilter(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	at, err := parseTime(r.URL.Query().Get("at"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	state, ok := src.HistoryAt(at)
+	if !ok {
+		http.Error(w, "no history retained for "+at.Format(time.RFC3339), http.StatusNotFound)
+		return
+	}
+	writeJSON(w, r, f.apply(state))
+}
+
+func (s *Server) handleHistoryRange(w http.ResponseWriter, r *http.Request) {
+	src, _, status, err := s.resolve(r)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+	f, err := parseFilter(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	from, err := parseTime(r.URL.Query().Get("from"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	to := time.Now()
+	if value := r.URL.Query().Get("to"); value != "" {
+		if to, err = parseTime(value); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	state, deltas, ok := src.HistoryRange(from, to)
+	if !ok {
+		http.Error(w, "no history retained for "+from.Format(time.RFC3339), http.StatusNotFound)
+		return
+	}
+
+	state = f.apply(state)
+	visible := make(map[string]bool)
+	f.track(state, visible)
+	filtered := make([]model.Delta, 0, len(deltas))
+	for _, d := range deltas {
+		if f.applyDelta(&d, visible) {
+			filtered = append(filtered, d)
+		}
+	}
+
+	writeJSON(w, r, model.HistoryRange{
+		From:   from.Format(time.RFC3339Nano),
+		To:     to.Format(time.RFC3339Nano),
+		State:  state,
+		Deltas: filtered,
+	})
+}
@@ -0,0 +1,72 @@
+package server
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/pettersolberg88/kube-ops-view-ng/internal/model"
+)
+
+func (s *Server) handleImages(w http.ResponseWriter, r *http.Request) {
+	src, _, status, err := s.resolve(r)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+	f, err := parseFilter(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, r, aggregateImages(f.apply(src.GetSnapshot()), r.URL.Query().Get("image")))
+}
+
+// aggregateImages groups the containers of a state by image and digest. If query is set
+// only images whose reference or digest contains it are returned.
+func aggregateImages(state model.ClusterState, query string) []model.Image {
+	images := make(map[string]*model.Image)
+	nodes := make(map[string]map[string]bool)
+	for _, pod := range state.Pods {
+		for _, list := range [][]model.ContainerInfo{pod.InitContainers, pod.Containers, pod.EphemeralContainers} {
+			for _, c := range list {
+				if query != "" && !strings.Contains(c.Image, query) && !strings.Contains(c.ImageID, query) {
+					continue
+				}
+				key := pod.Cluster + "\x00" + c.Image + "\x00" + c.ImageID
+				image, ok := images[key]
+				if !ok {
+					image = &model.Image{Cluster: pod.Cluster, Image: c.Image, ImageID: c.ImageID, Nodes: []string{}}
+					images[key] = image
+					nodes[key] = make(map[string]bool)
+				}
+				image.Containers = append(image.Containers, model.ImageContainer{
+					Namespace: pod.Namespace,
+					Pod:       pod.Name,
+					Container: c.Name,
+					NodeName:  pod.NodeName,
+				})
+				if pod.NodeName != "" && !nodes[key][pod.NodeName] {
+					nodes[key][pod.NodeName] = true
+					image.Nodes = append(image.Nodes, pod.NodeName)
+				}
+			}
+		}
+	}
+
+	result := make([]model.Image, 0, len(images))
+	for _, image := range images {
+		sort.Strings(image.Nodes)
+		result = append(result, *image)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Cluster != result[j].Cluster {
+			return result[i].Cluster < result[j].Cluster
+		}
+		if result[i].Image != result[j].Image {
+			return result[i].Image < result[j].Image
+		}
+		return result[i].ImageID < result[j].ImageID
+	})
+	return result
+}
@@ -0,0 +1,195 @@
+package server
+
+import (
+	"bytes"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pettersolberg88/kube-ops-view-ng/internal/k8s"
+	"github.com/pettersolberg88/kube-ops-view-ng/internal/model"
+)
+
+// metricsWriter writes metric families in the OpenMetrics text format
+type metricsWriter struct {
+	buf bytes.Buffer
+}
+
+// family writes the metadata of a metric family, counters are named without the _total suffix
+func (m *metricsWriter) family(name, metricType, help string) {
+	m.buf.WriteString("# TYPE " + name + " " + metricType + "\n")
+	m.buf.WriteString("# HELP " + name + " " + help + "\n")
+}
+
+// sample writes a single sample, labels are given as name/value pairs
+func (m *metricsWriter) sample(name string, value float64, labels ...string) {
+	m.buf.WriteString(name)
+	if len(labels) > 0 {
+		m.buf.WriteByte('{')
+		for i := 0; i+1 < len(labels); i += 2 {
+			if i > 0 {
+				m.buf.WriteByte(',')
+			}
+			m.buf.WriteString(labels[i] + "=\"" + escapeLabel(labels[i+1]) + "\"")
+		}
+		m.buf.WriteByte('}')
+	}
+	m.buf.WriteString(" " + strconv.FormatFloat(value, 'g', -1, 64) + "\n")
+}
+
+func escapeLabel(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `"`, `\"`)
+	return strings.ReplaceAll(value, "\n", `\n`)
+}
+
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	m := &metricsWriter{}
+
+	m.family("kube_ops_view_stream_subscribers", "gauge", "Number of connected /api/stream clients.")
+	m.sample("kube_ops_view_stream_subscribers", float64(s.streams.Load()))
+
+	clusters := s.clusters.Clusters()
+	m.family("kube_ops_view_cluster_up", "gauge", "Whether the cluster is synced and reachable.")
+	for _, c := range clusters {
+		up := 0.0
+		if c.Status == model.ClusterReady {
+			up = 1
+		}
+		m.sample("kube_ops_view_cluster_up", up, "cluster", c.Name, "status", c.Status)
+	}
+
+	watchers := s.clusters.Watchers()
+	merged := s.clusters.Stats()
+
+	// The merged stream of all clusters is reported with an empty cluster label
+	m.family("kube_ops_view_broadcasts_sent", "counter", "Updates delivered to subscriber channels.")
+	m.sample("kube_ops_view_broadcasts_sent_total", float64(merged.BroadcastsSent), "cluster", "")
+	for _, watcher := range watchers {
+		m.sample("kube_ops_view_broadcasts_sent_total", float64(watcher.Stats().BroadcastsSent), "cluster", watcher.Cluster())
+	}
+	m.family("kube_ops_view_broadcasts_dropped", "counter", "Updates dropped because a subscriber channel was full.")
+	m.sample("kube_ops_view_broadcasts_dropped_total", float64(merged.BroadcastsDropped), "cluster", "")
+	for _, watcher := range watchers {
+		m.sample("kube_ops_view_broadcasts_dropped_total", float64(watcher.Stats().BroadcastsDropped), "cluster", watcher.Cluster())
+	}
+	m.family("kube_ops_view_metrics_poll_duration_seconds", "summary", "Time spent polling metrics-server.")
+	for _, watcher := range watchers {
+		stats := watcher.Stats()
+		m.sample("kube_ops_view_metrics_poll_duration_seconds_sum", stats.MetricsPollDuration.Seconds(), "cluster", watcher.Cluster())
+		m.sample("kube_ops_view_metrics_poll_duration_seconds_count", float64(stats.MetricsPolls), "cluster", watcher.Cluster())
+	}
+	m.family("kube_ops_view_metrics_poll_errors", "counter", "Failed metrics-server list requests.")
+	for _, watcher := range watchers {
+		m.sample("kube_ops_view_metrics_poll_errors_total", float64(watcher.Stats().MetricsErrors), "cluster", watcher.Cluster())
+	}
+	m.family("kube_ops_view_informer_events", "counter", "Informer events handled by resource and type.")
+	for _, watcher := range watchers {
+		events := watcher.Stats().Events
+		keys := make([]k8s.EventKey, 0, len(events))
+		for k := range events {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			if keys[i].Resource != keys[j].Resource {
+				return keys[i].Resource < keys[j].Resource
+			}
+			return keys[i].Type < keys[j].Type
+		})
+		for _, k := range keys {
+			m.sample("kube_ops_view_informer_events_total", float64(events[k]), "cluster", watcher.Cluster(), "resource", k.Resource, "type", k.Type)
+		}
+	}
+
+	snapshots := make([]model.ClusterState, 0, len(watchers))
+	for _, watcher := range watchers {
+		snapshots = append(snapshots, watcher.GetSnapshot())
+	}
+
+	// Labelled by phase and health rather than the display status, which holds free-form
+	// reasons such as exit codes
+	m.family("kube_ops_view_pods", "gauge", "Number of pods by namespace, phase and health.")
+	for _, snapshot := range snapshots {
+		counts := make(map[[3]string]int)
+		for _, p := range snapshot.Pods {
+			counts[[3]string{p.Namespace, p.Phase, p.Health}]++
+		}
+		keys := make([][3]string, 0, len(counts))
+		for k := range counts {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			for n := range keys[i] {
+				if keys[i][n] != keys[j][n] {
+					return keys[i][n] < keys[j][n]
+				}
+			}
+			return false
+		})
+		for _, k := range keys {
+			m.sample("kube_ops_view_pods", float64(counts[k]), "cluster", snapshot.Cluster, "namespace", k[0], "phase", k[1], "health", k[2])
+		}
+	}
+
+	m.family("kube_ops_view_nodes", "gauge", "Number of nodes by status.")
+	for _, snapshot := range snapshots {
+		counts := make(map[string]int)
+		for _, n := range snapshot.Nodes {
+			counts[n.Status]++
+		}
+		statuses := make([]string, 0, len(counts))
+		for status := range counts {
+			statuses = append(statuses, status)
+		}
+		sort.Strings(statuses)
+		for _, status := range statuses {
+			m.sample("kube_ops_view_nodes", float64(counts[status]), "cluster", snapshot.Cluster, "status", status)
+		}
+	}
+
+	type allocation struct {
+		cluster, node                      string
+		cpuRequested, cpuAllocatable       float64
+		memoryRequested, memoryAllocatable float64
+	}
+	var allocations []*allocation
+	for _, snapshot := range snapshots {
+		for _, n := range snapshot.Nodes {
+			a := &allocation{
+				cluster:           snapshot.Cluster,
+				node:              n.Name,
+				cpuAllocatable:    float64(n.AllocatableValues.CPUMilli) / 1000,
+				memoryAllocatable: float64(n.AllocatableValues.MemoryBytes),
+			}
+			if n.Allocation != nil {
+				a.cpuRequested = float64(n.Allocation.CPURequestedMilli) / 1000
+				a.memoryRequested = float64(n.Allocation.MemoryRequestedBytes)
+			}
+			allocations = append(allocations, a)
+		}
+	}
+
+	m.family("kube_ops_view_node_cpu_requested_cores", "gauge", "CPU requested by the non-terminal pods on the node.")
+	for _, a := range allocations {
+		m.sample("kube_ops_view_node_cpu_requested_cores", a.cpuRequested, "cluster", a.cluster, "node", a.node)
+	}
+	m.family("kube_ops_view_node_cpu_allocatable_cores", "gauge", "Allocatable CPU of the node.")
+	for _, a := range allocations {
+		m.sample("kube_ops_view_node_cpu_allocatable_cores", a.cpuAllocatable, "cluster", a.cluster, "node", a.node)
+	}
+	m.family("kube_ops_view_node_memory_requested_bytes", "gauge", "Memory requested by the non-terminal pods on the node.")
+	for _, a := range allocations {
+		m.sample("kube_ops_view_node_memory_requested_bytes", a.memoryRequested, "cluster", a.cluster, "node", a.node)
+	}
+	m.family("kube_ops_view_node_memory_allocatable_bytes", "gauge", "Allocatable memory of the node.")
+	for _, a := range allocations {
+		m.sample("kube_ops_view_node_memory_allocatable_bytes", a.memoryAllocatable, "cluster", a.cluster, "node", a.node)
+	}
+
+	m.buf.WriteString("# EOF\n")
+
+	w.Header().Set("Content-Type", "application/openmetrics-text; version=1.0.0; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write(m.buf.Bytes())
+}
@@ -5,22 +5,36 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/andybalholm/brotli"
 	"github.com/pettersolberg88/kube-ops-view-ng/internal/k8s"
+	"github.com/pettersolberg88/kube-ops-view-ng/internal/model"
 )
 
 type Server struct {
-	watcher        *k8s.Watcher
+	clusters       *k8s.ClusterSet
 	mux            *http.ServeMux
 	lastUpdateTime int64
+	streams        atomic.Int64 // Connected /api/stream clients
 }
 
-func NewServer(watcher *k8s.Watcher) *Server {
+// source provides cluster state, either a single Watcher or the merged ClusterSet
+type source interface {
+	GetSnapshot() model.ClusterState
+	Subscribe() chan model.ClusterState
+	Unsubscribe(ch chan model.ClusterState)
+	HistoryAt(t time.Time) (model.ClusterState, bool)
+	HistoryRange(from, to time.Time) (model.ClusterState, []model.Delta, bool)
+	Events(namespace, object string) []model.Event
+}
+
+func NewServer(clusters *k8s.ClusterSet) *Server {
 	s := &Server{
-		watcher:        watcher,
+		clusters:       clusters,
 		mux:            http.NewServeMux(),
 		lastUpdateTime: 0,
 	}
@@ -32,8 +46,17 @@ func NewServer(watcher *k8s.Watcher) *Server {
 func (s *Server) routes() {
 	s.mux.HandleFunc("/api/alive", s.handleAlive)
 	s.mux.HandleFunc("/api/ready", s.handleReady)
+	s.mux.HandleFunc("/api/clusters", s.handleClusters)
 	s.mux.HandleFunc("/api/snapshot", s.handleSnapshot)
 	s.mux.HandleFunc("/api/stream", s.handleStream)
+	s.mux.HandleFunc("/api/events", s.handleEvents)
+	s.mux.HandleFunc("/api/images", s.handleImages)
+	s.mux.HandleFunc("/api/workloads", s.handleWorkloads)
+	s.mux.HandleFunc("/api/rollouts", s.handleRollouts)
+	s.mux.HandleFunc("/api/services", s.handleServices)
+	s.mux.HandleFunc("/api/history", s.handleHistory)
+	s.mux.HandleFunc("/api/history/range", s.handleHistoryRange)
+	s.mux.HandleFunc("/metrics", s.handleMetrics)
 
 	// Serve static files
 	fs := http.FileServer(http.Dir("web/dist"))
@@ -69,8 +92,8 @@ func (s *Server) handleReady(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *Server) updateReadyStatus() {
-	ch := s.watcher.Subscribe()
-	defer s.watcher.Unsubscribe(ch)
+	ch := s.clusters.Subscribe()
+	defer s.clusters.Unsubscribe(ch)
 
 	for {
 		select {
@@ -83,9 +106,108 @@ func (s *Server) updateReadyStatus() {
 	}
 }
 
+// resolve returns the state source and watchers selected by the cluster query parameter,
+// all clusters are merged when it is empty
+func (s *Server) resolve(r *http.Request) (source, []*k8s.Watcher, int, error) {
+	name := r.URL.Query().Get("cluster")
+	if name == "" {
+		return s.clusters, s.clusters.Watchers(), http.StatusOK, nil
+	}
+	if !s.clusters.Has(name) {
+		return nil, nil, http.StatusNotFound, fmt.Errorf("unknown cluster %q", name)
+	}
+	watcher := s.clusters.Watcher(name)
+	if watcher == nil {
+		return nil, nil, http.StatusServiceUnavailable, fmt.Errorf("cluster %q is degraded", name)
+	}
+	return watcher, []*k8s.Watcher{watcher}, http.StatusOK, nil
+}
+
+func (s *Server) handleClusters(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, r, s.clusters.Clusters())
+}
+
 func (s *Server) handleSnapshot(w http.ResponseWriter, r *http.Request) {
-	snapshot := s.watcher.GetSnapshot()
+	src, _, status, err := s.resolve(r)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+	f, err := parseFilter(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, r, f.apply(src.GetSnapshot()))
+}
+
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	src, _, status, err := s.resolve(r)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+	events := src.Events(r.URL.Query().Get("namespace"), r.URL.Query().Get("object"))
+	if events == nil {
+		events = []model.Event{}
+	}
+	writeJSON(w, r, events)
+}
+
+func (s *Server) handleWorkloads(w http.ResponseWriter, r *http.Request) {
+	src, _, status, err := s.resolve(r)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+	f, err := parseFilter(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, r, f.apply(src.GetSnapshot()).Workloads)
+}
 
+func (s *Server) handleRollouts(w http.ResponseWriter, r *http.Request) {
+	src, _, status, err := s.resolve(r)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+	f, err := parseFilter(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, r, f.apply(src.GetSnapshot()).Rollouts)
+}
+
+func (s *Server) handleServices(w http.ResponseWriter, r *http.Request) {
+	src, _, status, err := s.resolve(r)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+	f, err := parseFilter(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	services := f.apply(src.GetSnapshot()).Services
+	if severity := r.URL.Query().Get("severity"); severity != "" {
+		matching := make([]model.Service, 0, len(services))
+		for _, svc := range services {
+			if svc.Severity == severity {
+				matching = append(matching, svc)
+			}
+		}
+		services = matching
+	}
+	writeJSON(w, r, services)
+}
+
+// writeJSON encodes v as the response body, brotli compressed if the client supports it
+func writeJSON(w http.ResponseWriter, r *http.Request, v any) {
 	w.Header().Set("Content-Type", "application/json")
 	acceptEncoding := r.Header.Get("Accept-Encoding")
 	supportsBrotil := strings.Contains(acceptEncoding, "br")
@@ -96,7 +218,7 @@ func (s *Server) handleSnapshot(w http.ResponseWriter, r *http.Request) {
 			Quality: 3,
 			LGWin:   21,
 		})
-		if err := json.NewEncoder(bw).Encode(snapshot); err != nil {
+		if err := json.NewEncoder(bw).Encode(v); err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
@@ -105,13 +227,27 @@ func (s *Server) handleSnapshot(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 	} else {
-		if err := json.NewEncoder(w).Encode(snapshot); err != nil {
+		if err := json.NewEncoder(w).Encode(v); err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 		}
 	}
 }
 
 func (s *Server) handleStream(w http.ResponseWriter, r *http.Request) {
+	src, watchers, status, err := s.resolve(r)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+	f, err := parseFilter(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.streams.Add(1)
+	defer s.streams.Add(-1)
+
 	// Set headers for SSE
 	w.Header().Set("Content-Type", "text/event-stream")
 	w.Header().Set("Cache-Control", "no-cache")
@@ -133,17 +269,7 @@ func (s *Server) handleStream(w http.ResponseWriter, r *http.Request) {
 		defer brotliWriter.Close()
 	}
 
-	// Subscribe to updates
-	ch := s.watcher.Subscribe()
-	defer s.watcher.Unsubscribe(ch)
-
-	// Send initial snapshot
-	snapshot := s.watcher.GetSnapshot()
-	data, err := json.Marshal(snapshot)
-	if err == nil {
-		writer.Write([]byte("data: "))
-		writer.Write(data)
-		writer.Write([]byte("\n\n"))
+	flush := func() {
 		if supportsBrotli {
 			brotliWriter.Flush()
 		}
@@ -152,6 +278,23 @@ func (s *Server) handleStream(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	if r.URL.Query().Get("mode") == "delta" {
+		s.streamDeltas(r, writer, flush, watchers, f)
+		return
+	}
+
+	// Subscribe to updates
+	ch := src.Subscribe()
+	defer src.Unsubscribe(ch)
+
+	// Send initial snapshot
+	snapshot := f.apply(src.GetSnapshot())
+	data, err := json.Marshal(snapshot)
+	if err == nil {
+		writeEvent(writer, "", "", data)
+		flush()
+	}
+
 	// Stream updates
 
 	updateCount := 0
@@ -163,21 +306,106 @@ func (s *Server) handleStream(w http.ResponseWriter, r *http.Request) {
 			if !ok {
 				return
 			}
-			data, err := json.Marshal(state)
+			data, err := json.Marshal(f.apply(state))
 			if err != nil {
 				continue
 			}
 			updateCount++
-			writer.Write([]byte(fmt.Sprintf("id: update-%d\n", updateCount)))
-			writer.Write([]byte("data: "))
-			writer.Write(data)
-			writer.Write([]byte("\n\n"))
-			if supportsBrotli {
-				brotliWriter.Flush()
+			writeEvent(writer, "", fmt.Sprintf("update-%d", updateCount), data)
+			flush()
+		}
+	}
+}
+
+// streamDeltas sends a snapshot per cluster followed by typed delta events. The event id
+// is the cluster revision, so a client seeing a gap can reconnect to resync. If this
+// subscriber misses a batch the server sends a fresh snapshot of that cluster itself.
+// Deltas are filtered per subscriber, a pod that stops matching is sent as a delete.
+func (s *Server) streamDeltas(r *http.Request, writer io.Writer, flush func(), watchers []*k8s.Watcher, f *filter) {
+	type batch struct {
+		watcher *k8s.Watcher
+		deltas  []model.Delta
+	}
+
+	ctx := r.Context()
+	batches := make(chan batch)
+	for _, watcher := range watchers {
+		ch := watcher.SubscribeDeltas()
+		defer watcher.UnsubscribeDeltas(ch)
+		go func() {
+			for deltas := range ch {
+				select {
+				case batches <- batch{watcher: watcher, deltas: deltas}:
+				case <-ctx.Done():
+					return
+				}
 			}
-			if f, ok := w.(http.Flusher); ok {
-				f.Flush()
+		}()
+	}
+
+	revisions := make(map[*k8s.Watcher]uint64)
+	visible := make(map[string]bool)
+	sendSnapshot := func(watcher *k8s.Watcher) bool {
+		snapshot := f.apply(watcher.GetSnapshot())
+		f.track(snapshot, visible)
+		data, err := json.Marshal(snapshot)
+		if err != nil {
+			return false
+		}
+		writeEvent(writer, "snapshot", strconv.FormatUint(snapshot.Revision, 10), data)
+		revisions[watcher] = snapshot.Revision
+		return true
+	}
+
+	for _, watcher := range watchers {
+		if !sendSnapshot(watcher) {
+			return
+		}
+	}
+	flush()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case b := <-batches:
+			for _, d := range b.deltas {
+				revision := revisions[b.watcher]
+				if d.Revision <= revision {
+					// Already part of the snapshot
+					continue
+				}
+				if d.Revision != revision+1 {
+					// Missed a batch, resync
+					if !sendSnapshot(b.watcher) {
+						return
+					}
+					break
+				}
+				revisions[b.watcher] = d.Revision
+				if !f.applyDelta(&d, visible) {
+					continue
+				}
+				data, err := json.Marshal(d)
+				if err != nil {
+					continue
+				}
+				writeEvent(writer, d.Type, strconv.FormatUint(d.Revision, 10), data)
 			}
+			flush()
 		}
 	}
 }
+
+// writeEvent writes a single SSE message, event and id are omitted when empty
+func writeEvent(writer io.Writer, event string, id string, data []byte) {
+	if event != "" {
+		writer.Write([]byte("event: " + event + "\n"))
+	}
+	if id != "" {
+		writer.Write([]byte("id: " + id + "\n"))
+	}
+	writer.Write([]byte("data: "))
+	writer.Write(data)
+	writer.Write([]byte("\n\n"))
+}
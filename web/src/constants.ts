@@ -9,6 +9,7 @@ export const COLORS = {
             ready: '#2ecc71',
             notReady: '#e5533d',
             cordoned: '#f2b705',
+            pressure: '#e67e22',
         },
         barBackground: '#14181d',
         barInformal: '#4c8dff',
@@ -1,6 +1,6 @@
 import {Container, Graphics, Text, TextStyle} from "pixi.js";
 import {NodeContainer, Pod, Node, PodContainer} from "./types.ts";
-import {getMetricColor, getNodeColor, parseMemoryValue, parseMetricValue} from "./utils.ts";
+import {cpuValue, getMetricColor, getNodeColor, memoryValue} from "./utils.ts";
 import {animatePodPosition, animatePodRemoval, animatePodZoomIn, drawPod, getPodTooltipText} from "./pod.ts";
 import {COLORS, LAYOUT} from "./constants.ts";
 
@@ -36,15 +36,15 @@ export function renderPodsOnNode(container: Container, pods: Pod[], podsPerRow:
                 }
                 return timeA - timeB;
             case 'cpu':
-                const cpuA = parseMetricValue(a.resources?.cpu_requested || '0');
-                const cpuB = parseMetricValue(b.resources?.cpu_requested || '0');
+                const cpuA = cpuValue(a.resources?.cpu_requested_milli, a.resources?.cpu_requested);
+                const cpuB = cpuValue(b.resources?.cpu_requested_milli, b.resources?.cpu_requested);
                 if ((cpuB - cpuA) == 0 ){
                     return a.name.localeCompare(b.name);
                 }
                 return cpuB - cpuA; // Descending
             case 'memory':
-                const memA = parseMemoryValue(a.resources?.memory_requested || '0');
-                const memB = parseMemoryValue(b.resources?.memory_requested || '0');
+                const memA = memoryValue(a.resources?.memory_requested_bytes, a.resources?.memory_requested);
+                const memB = memoryValue(b.resources?.memory_requested_bytes, b.resources?.memory_requested);
                 if ((memB - memA) == 0 ){
                     return a.name.localeCompare(b.name);
                 }
@@ -68,7 +68,7 @@ export function renderPodsOnNode(container: Container, pods: Pod[], podsPerRow:
     const currentPodIds = new Set<string>();
 
     pods.forEach((pod, index) => {
-        const podId = `${pod.namespace}/${pod.name}`;
+        const podId = `${pod.cluster}/${pod.namespace}/${pod.name}`;
         currentPodIds.add(podId);
 
         const col = index % cols;
@@ -239,20 +239,25 @@ export function renderNodeMetrics(container: NodeContainer, node: Node, pods: Po
 
     let requestedCpu = 0;
     let requestedMem = 0;
-    pods.forEach(pod => {
-        if (pod.resources) {
-            requestedCpu += parseMetricValue(pod.resources.cpu_requested);
-            requestedMem += parseMemoryValue(pod.resources.memory_requested);
-        }
-    });
+    if (node.allocation) {
+        requestedCpu = node.allocation.cpu_requested_milli / 1000;
+        requestedMem = node.allocation.memory_requested_bytes;
+    } else {
+        pods.forEach(pod => {
+            if (pod.resources) {
+                requestedCpu += cpuValue(pod.resources.cpu_requested_milli, pod.resources.cpu_requested);
+                requestedMem += memoryValue(pod.resources.memory_requested_bytes, pod.resources.memory_requested);
+            }
+        });
+    }
 
     let cpuPercent = 0;
     let cpuReqPercent = 0;
-    const cpuCapacity = parseMetricValue(node.capacity.cpu);
-    const cpuAllocatable = parseMetricValue(node.allocatable?.cpu || node.capacity.cpu);
+    const cpuCapacity = cpuValue(node.capacity_values?.cpu_milli, node.capacity.cpu);
+    const cpuAllocatable = cpuValue(node.allocatable_values?.cpu_milli, node.allocatable?.cpu || node.capacity.cpu);
     const cpuAvailable = cpuAllocatable - requestedCpu;
     const cpuReserved = Math.max(0, cpuCapacity - cpuAllocatable);
-    const cpuUsed = parseMetricValue(node.metrics.cpu);
+    const cpuUsed = cpuValue(node.metrics.cpu_milli, node.metrics.cpu);
 
     if (cpuCapacity > 0 && cpuAllocatable > 0) {
         cpuPercent = Math.min((cpuUsed / cpuCapacity) * 100, 100);
@@ -261,11 +266,11 @@ export function renderNodeMetrics(container: NodeContainer, node: Node, pods: Po
 
     let memPercent = 0;
     let memReqPercent = 0;
-    const memCapacity = parseMemoryValue(node.capacity.memory);
-    const memAllocatable = parseMemoryValue(node.allocatable?.memory || node.capacity.memory);
+    const memCapacity = memoryValue(node.capacity_values?.memory_bytes, node.capacity.memory);
+    const memAllocatable = memoryValue(node.allocatable_values?.memory_bytes, node.allocatable?.memory || node.capacity.memory);
     const memAvailable = memAllocatable - requestedMem;
     const memReserved = Math.max(0, memCapacity - memAllocatable);
-    const memUsed = parseMemoryValue(node.metrics.memory);
+    const memUsed = memoryValue(node.metrics.memory_bytes, node.metrics.memory);
 
     if (memCapacity > 0) {
         memPercent = Math.min((memUsed / memCapacity) * 100, 100);
@@ -295,6 +300,13 @@ Memory:
 Pods:
   Used     : ${podUsed}
   Capacity : ${podCapacity}`;
+        const extended = Object.keys(node.allocation?.requests || {}).filter(name => name != 'cpu' && name != 'memory');
+        if (extended.length > 0) {
+            resourceTooltipText.text += `\n\nOther:`;
+            extended.sort().forEach(name => {
+                resourceTooltipText.text += `\n  ${name}: ${node.allocation!.requests![name]}/${node.allocatable[name] || '-'}`;
+            });
+        }
 
     }
     const resourceTooltipBackground = (container.getChildByLabel('node-resource-tooltip-bg', true) as Graphics);
@@ -430,7 +442,7 @@ export function createNodeContainer(node: Node): NodeContainer {
     resourceBars.addChild(cpuBarBg);
 
     if (node.capacity && node.capacity.cpu) {
-        const numCores = Math.ceil(parseMetricValue(node.capacity.cpu));
+        const numCores = Math.ceil(cpuValue(node.capacity_values?.cpu_milli, node.capacity.cpu));
         if (numCores > 1) {
             const dividerContainer = new Container();
             dividerContainer.label = 'node-cpu-dividers';
@@ -569,6 +581,19 @@ Version: ${node.version || 'N/A'}
 OS:      ${node.os_image || 'N/A'}
 Kernel:  ${node.kernel_version || 'N/A'}
 Runtime: ${node.container_runtime_version || 'N/A'}`;
+        if (node.instance_type || node.node_pool) {
+            nodeTooltip.text += `\nType:    ${[node.instance_type, node.node_pool, node.arch].filter(Boolean).join(', ')}`;
+        }
+        const pressure = (node.conditions || []).filter(c => c.type != 'Ready' && c.status == 'True');
+        if (pressure.length > 0) {
+            nodeTooltip.text += `\nIssues:  ${pressure.map(c => c.type).join(', ')}`;
+        }
+        if (node.taints && node.taints.length > 0) {
+            nodeTooltip.text += `\nTaints:  ${node.taints.map(t => `${t.key}:${t.effect}`).join(', ')}`;
+        }
+        if (node.attached_volumes && node.attached_volumes.length > 0) {
+            nodeTooltip.text += `\nVolumes: ${node.attached_volumes.map(v => `${v.driver} ${v.attached}/${v.limit || '-'}`).join(', ')}`;
+        }
     }
 
 
@@ -594,7 +619,7 @@ Runtime: ${node.container_runtime_version || 'N/A'}`;
     if (dividerContainer) {
         dividerContainer.removeChildren();
         if (node.capacity && node.capacity.cpu) {
-            const numCores = Math.ceil(parseMetricValue(node.capacity.cpu));
+            const numCores = Math.ceil(cpuValue(node.capacity_values?.cpu_milli, node.capacity.cpu));
             if (numCores > 1) {
                 const step = barHeight / numCores;
                 for (let i = 1; i < numCores; i++) {
@@ -1,6 +1,6 @@
 import {BlurFilter, Container, Graphics, RenderLayer} from "pixi.js";
 import {NodeContainer, Pod, PodContainer} from "./types.ts";
-import {getPodColor, parseMemoryValue, parseMetricValue} from "./utils.ts";
+import {cpuValue, getPodColor, memoryValue} from "./utils.ts";
 
 export function drawPod(container: Container, pod: Pod, size: number) {
     const g = new Graphics();
@@ -14,13 +14,13 @@ export function drawPod(container: Container, pod: Pod, size: number) {
     g.rect(0, 0, size, size);
     g.stroke({width: 3, color: borderColor});
 
-    const cpuUsed = parseMetricValue(pod.metrics?.cpu || '0');
-    const cpuReq = parseMetricValue(pod.resources?.cpu_requested || '0');
-    const cpuLim = parseMetricValue(pod.resources?.cpu_limit || '0');
+    const cpuUsed = cpuValue(pod.metrics?.cpu_milli, pod.metrics?.cpu);
+    const cpuReq = cpuValue(pod.resources?.cpu_requested_milli, pod.resources?.cpu_requested);
+    const cpuLim = cpuValue(pod.resources?.cpu_limit_milli, pod.resources?.cpu_limit);
 
-    const memUsed = parseMemoryValue(pod.metrics?.memory || '0');
-    const memReq = parseMemoryValue(pod.resources?.memory_requested || '0');
-    const memLim = parseMemoryValue(pod.resources?.memory_limit || '0');
+    const memUsed = memoryValue(pod.metrics?.memory_bytes, pod.metrics?.memory);
+    const memReq = memoryValue(pod.resources?.memory_requested_bytes, pod.resources?.memory_requested);
+    const memLim = memoryValue(pod.resources?.memory_limit_bytes, pod.resources?.memory_limit);
 
     const cpuMax = cpuLim > 0 ? cpuLim : (cpuReq > 0 ? cpuReq : (cpuUsed > 0 ? cpuUsed : 1));
     const memMax = memLim > 0 ? memLim : (memReq > 0 ? memReq : (memUsed > 0 ? memUsed : 1));
@@ -76,7 +76,22 @@ export function getPodTooltipText(pod: Pod): string {
     let text = `${pod.name}\n`;
     text += `Namespace : ${pod.namespace}\n`;
     text += `Status    : ${statusText}\n`;
+    if (pod.workload_kind) {
+        text += `Workload  : ${pod.workload_kind}/${pod.workload_name}\n`;
+        if (pod.workload_revision) {
+            text += `Revision  : ${pod.workload_revision}${pod.current_revision ? ' (current)' : ' (old)'}\n`;
+        }
+    }
     text += `Start Time: ${pod.start_time || 'N/A'}\n`;
+    if (pod.qos_class) {
+        text += `QoS Class : ${pod.qos_class}\n`;
+    }
+    if (pod.volume_claims?.length) {
+        text += `Volumes   : ${pod.volume_claims.map(c => `${c.name} (${c.phase || 'Unknown'})`).join(', ')}\n`;
+    }
+    if (pod.services?.length) {
+        text += `Services  : ${pod.services.map(s => s.ready ? s.name : `${s.name} (not ready)`).join(', ')}\n`;
+    }
 
     text += `Labels    :\n`;
     if (pod.labels) {
@@ -87,17 +102,31 @@ export function getPodTooltipText(pod: Pod): string {
 
     text += `Containers:\n`;
     if (pod.containers) {
+        for (const c of pod.init_containers || []) {
+            const kind = c.sidecar ? 'sidecar' : 'init';
+            text += `  ${c.name} [${kind}]: ${c.state} (${c.restarts} restarts)\n`;
+        }
         for (const c of pod.containers) {
-            text += `  ${c.name}: ${c.state} (${c.restarts} restarts)\n`;
+            text += `  ${c.name}: ${c.state}${c.reason ? ' ' + c.reason : ''} (${c.restarts} restarts)${c.oom_killed ? ' OOMKilled' : ''}\n`;
+            text += `    ${c.image}\n`;
+            if (c.last_state) {
+                text += `    last: ${c.last_state.reason} (exit ${c.last_state.exit_code}) at ${c.last_state.finished_at || '-'}\n`;
+            }
+            if (c.metrics) {
+                text += `    cpu ${c.metrics.cpu}/${c.resources?.cpu_limit || '-'}, mem ${c.metrics.memory}/${c.resources?.memory_limit || '-'}\n`;
+            }
+        }
+        for (const c of pod.ephemeral_containers || []) {
+            text += `  ${c.name} [ephemeral]: ${c.state}\n`;
         }
 
-        const cpuReq = parseMetricValue(pod.resources?.cpu_requested || '0');
-        const cpuLim = parseMetricValue(pod.resources?.cpu_limit || '0');
-        const cpuUsed = parseMetricValue(pod.metrics?.cpu || '0');
+        const cpuReq = cpuValue(pod.resources?.cpu_requested_milli, pod.resources?.cpu_requested);
+        const cpuLim = cpuValue(pod.resources?.cpu_limit_milli, pod.resources?.cpu_limit);
+        const cpuUsed = cpuValue(pod.metrics?.cpu_milli, pod.metrics?.cpu);
 
-        const memReq = parseMemoryValue(pod.resources?.memory_requested || '0');
-        const memLim = parseMemoryValue(pod.resources?.memory_limit || '0');
-        const memUsed = parseMemoryValue(pod.metrics?.memory || '0');
+        const memReq = memoryValue(pod.resources?.memory_requested_bytes, pod.resources?.memory_requested);
+        const memLim = memoryValue(pod.resources?.memory_limit_bytes, pod.resources?.memory_limit);
+        const memUsed = memoryValue(pod.metrics?.memory_bytes, pod.metrics?.memory);
 
         const useCores = cpuReq >= 1 || cpuLim >= 1 || cpuUsed >= 1;
         const coreUnit = useCores ? 'Cores' : 'mCores';
@@ -117,6 +146,15 @@ export function getPodTooltipText(pod: Pod): string {
         text += `  Requested: ${(memReq / memDivisor).toFixed(3)}\n`;
         text += `  Limit:     ${(memLim / memDivisor).toFixed(3)}\n`;
         text += `  Used:      ${(memUsed / memDivisor).toFixed(3)}\n`;
+
+        // Extended resources such as GPUs and hugepages
+        const extended = Object.keys(pod.resources?.requests || {}).filter(name => name != 'cpu' && name != 'memory');
+        if (extended.length > 0) {
+            text += `Other:\n`;
+            extended.sort().forEach(name => {
+                text += `  ${name}: ${pod.resources!.requests![name]}/${pod.resources?.limits?.[name] || '-'}\n`;
+            });
+        }
     }
     return text;
 }
@@ -3,20 +3,76 @@ import {Container} from "pixi.js";
 export interface Metrics {
     cpu: string;
     memory: string;
+    cpu_milli?: number;
+    memory_bytes?: number;
+}
+
+export interface KubeEvent {
+    cluster: string;
+    namespace: string;
+    kind: string;
+    name: string;
+    type: string;
+    reason: string;
+    message: string;
+    source: string;
+    count: number;
+    first_seen: string;
+    last_seen: string;
+}
+
+export interface NodeCondition {
+    type: string;
+    status: string;
+    reason: string;
+    message: string;
+    last_transition_time: string;
+}
+
+export interface Taint {
+    key: string;
+    value: string;
+    effect: string;
+    time_added?: string;
 }
 
 export interface Node {
+    cluster: string;
     name: string;
     status: string;
+    conditions: NodeCondition[];
+    severity: string;
+    taints: Taint[];
     roles: string[];
     labels: { [key: string]: string };
     capacity: { [key: string]: string };
     allocatable: { [key: string]: string };
+    capacity_values?: NodeResources;
+    allocatable_values?: NodeResources;
     metrics?: Metrics;
     version?: string;
     kernel_version?: string;
     os_image?: string;
     container_runtime_version?: string;
+    zone?: string;
+    region?: string;
+    instance_type?: string;
+    arch?: string;
+    os?: string;
+    provider_id?: string;
+    node_pool?: string;
+    attached_volumes?: VolumeDriver[];
+    allocation?: NodeAllocation;
+    recent_events?: KubeEvent[];
+}
+
+export interface ContainerTermination {
+    reason: string;
+    message?: string;
+    exit_code: number;
+    signal?: number;
+    started_at?: string;
+    finished_at?: string;
 }
 
 export interface ContainerInfo {
@@ -24,6 +80,17 @@ export interface ContainerInfo {
     state: string;
     ready: boolean;
     restarts: number;
+    started: boolean;
+    sidecar?: boolean;
+    image: string;
+    image_id: string;
+    image_pull_policy: string;
+    reason?: string;
+    message?: string;
+    last_state?: ContainerTermination;
+    oom_killed?: boolean;
+    metrics?: Metrics;
+    resources?: PodResources;
 }
 
 export interface PodResources {
@@ -31,12 +98,22 @@ export interface PodResources {
     cpu_limit: string;
     memory_requested: string;
     memory_limit: string;
+    cpu_requested_milli?: number;
+    cpu_limit_milli?: number;
+    memory_requested_bytes?: number;
+    memory_limit_bytes?: number;
+    requests?: { [key: string]: string };
+    limits?: { [key: string]: string };
 }
 
 export interface Pod {
+    id: string;
+    cluster: string;
     name: string;
     namespace: string;
     status: string;
+    phase: string;
+    health: 'healthy' | 'progressing' | 'warning' | 'failed';
     node_name: string;
     labels: { [key: string]: string };
     metrics?: Metrics;
@@ -44,13 +121,132 @@ export interface Pod {
     start_time?: string;
     restarts?: number;
     containers?: ContainerInfo[];
+    init_containers?: ContainerInfo[];
+    ephemeral_containers?: ContainerInfo[];
     resources?: PodResources;
+    qos_class?: string;
     controller_type: string;
+    workload_kind?: string;
+    workload_name?: string;
+    workload_revision?: string;
+    current_revision: boolean;
+    services?: PodService[];
+    volume_claims?: VolumeClaim[];
+    recent_events?: KubeEvent[];
+}
+
+export interface ClusterInfo {
+    name: string;
+    status: string;
+    error?: string;
+    revision: number;
+    nodes: number;
+    pods: number;
+}
+
+export interface WorkloadCondition {
+    type: string;
+    status: string;
+    reason: string;
+    message: string;
+    last_transition_time: string;
+}
+
+export interface Workload {
+    cluster: string;
+    kind: string;
+    namespace: string;
+    name: string;
+    replicas: number;
+    ready_replicas: number;
+    updated_replicas: number;
+    available_replicas: number;
+    conditions: WorkloadCondition[];
+    selector: string;
+}
+
+export interface Rollout {
+    cluster: string;
+    kind: string;
+    namespace: string;
+    name: string;
+    revision: string;
+    desired: number;
+    updated: number;
+    updated_ready: number;
+    old_remaining: number;
+    complete: boolean;
+    last_progress: string;
+    stalled_since?: string;
+}
+
+export interface Service {
+    cluster: string;
+    namespace: string;
+    name: string;
+    type: string;
+    cluster_ip: string;
+    selector?: { [key: string]: string };
+    ready: number;
+    not_ready: number;
+    terminating: number;
+    severity: 'ok' | 'warning' | 'critical';
+}
+
+export interface VolumeClaim {
+    volume: string;
+    name: string;
+    storage_class: string;
+    capacity: string;
+    phase: string;
+    access_modes: string[];
+}
+
+export interface NodeResources {
+    cpu_milli: number;
+    memory_bytes: number;
+    ephemeral_storage_bytes: number;
+    pods: number;
+}
+
+export interface NodeAllocation {
+    pods: number;
+    pods_allocatable: number;
+    cpu_requested_milli: number;
+    cpu_limit_milli: number;
+    memory_requested_bytes: number;
+    memory_limit_bytes: number;
+    ephemeral_storage_requested_bytes: number;
+    ephemeral_storage_limit_bytes: number;
+    extended_requested?: { [key: string]: number };
+    extended_limits?: { [key: string]: number };
+    requests?: { [key: string]: string };
+    limits?: { [key: string]: string };
+    cpu_usage_milli: number;
+    memory_usage_bytes: number;
+}
+
+export interface VolumeDriver {
+    driver: string;
+    attached: number;
+    limit: number;
+}
+
+export interface PodService {
+    name: string;
+    ready: boolean;
+    terminating?: boolean;
 }
 
 export interface ClusterState {
+    cluster?: string;
+    revision: number;
+    clusters?: ClusterInfo[];
     nodes: Node[];
     pods: Pod[];
+    workloads: Workload[];
+    rollouts: Rollout[];
+    services: Service[];
 }
 
 export type NodeContainer = Container
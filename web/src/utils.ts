@@ -28,6 +28,20 @@ export function parseMemoryValue(metric: string): number {
     return num; // assume bytes
 }
 
+// Node names are only unique within a cluster, the merged stream can hold several
+export function nodeKey(cluster: string, name: string): string {
+    return `${cluster}/${name}`;
+}
+
+// Numeric values from the backend take precedence over parsing the display string
+export function cpuValue(milli: number | undefined, display: string | undefined): number {
+    return milli !== undefined ? milli / 1000 : parseMetricValue(display || '0');
+}
+
+export function memoryValue(bytes: number | undefined, display: string | undefined): number {
+    return bytes !== undefined ? bytes : parseMemoryValue(display || '0');
+}
+
 export function getMetricColor(percent: number): string {
     if (percent < 60) return COLORS.pod.metric.good;
     if (percent < 80) return COLORS.pod.metric.warning;
@@ -38,8 +52,10 @@ export function getNodeColor(node: Node): string {
     if (node.status == 'NotReady') {
         return COLORS.node.header.notReady;
     }
-    else if (node.status == 'Cordoned') {
+    else if (node.status == 'Cordoned' || node.status == 'Draining') {
         return COLORS.node.header.cordoned;
+    }else if (node.severity == 'warning' || node.status == 'ScaleDownCandidate') {
+        return COLORS.node.header.pressure;
     }else if (node.roles.includes('control-plane') || node.roles.includes('master')) {
         return COLORS.node.header.controlPlane;
     }
@@ -62,7 +78,17 @@ export function getPodColor(pod: Pod): string {
         case 'Running':
             return COLORS.pod.status.running;
         case 'Unknown':
+            return COLORS.pod.status.unknown;
         default:
+            // Reasons such as Init:0/2, OOMKilled or Evicted
+            switch (pod.health) {
+                case 'failed':
+                    return COLORS.pod.status.failed;
+                case 'progressing':
+                    return COLORS.pod.status.pending;
+                case 'healthy':
+                    return pod.phase == 'Succeeded' ? COLORS.pod.status.completed : COLORS.pod.status.running;
+            }
             return COLORS.pod.status.unknown;
     }
 }
@@ -3,6 +3,7 @@ import {ClusterState, Node, NodeContainer, NodeLayout} from './types';
 import {LAYOUT, TEXTS} from './constants';
 import {animatePodErrors} from "./pod.ts";
 import {drawZone} from './zone.ts'
+import {nodeKey} from "./utils.ts";
 
 // Interfaces for custom container properties
 
@@ -21,7 +22,7 @@ export class Visualizer {
     private initialLoadComplete: boolean = false;
     private isFilterChange: boolean = false;
 
-    private state: ClusterState = {nodes: [], pods: []}
+    private state: ClusterState = {revision: 0, nodes: [], pods: [], workloads: [], rollouts: [], services: []}
 
     constructor(app: Application) {
         this.app = app;
@@ -285,10 +286,15 @@ export class Visualizer {
 
         if (pendingPods.length > 0) {
             nodesList.push({
+                cluster: '',
                 name: TEXTS.pending_zone.name,
                 status: 'Pending',
+                conditions: [],
+                severity: 'ok',
+                taints: [],
                 roles: ['pending'],
-                labels: { 'failure-domain.beta.kubernetes.io/zone': 'zz-Quantum space' },
+                labels: {},
+                zone: 'zz-Quantum space',
                 capacity: {
                     pods: pendingPods.length.toString(),
                     cpu: '0',
@@ -304,14 +310,14 @@ export class Visualizer {
         }
 
         // Track which nodes are in the new state
-        const currentNodeNames = new Set(nodesList.map(n => n.name));
+        const currentNodeKeys = new Set(nodesList.map(n => nodeKey(n.cluster, n.name)));
 
         // Remove nodes that no longer exist
-        this.nodes.forEach((container, nodeName) => {
-            if (!currentNodeNames.has(nodeName)) {
+        this.nodes.forEach((container, key) => {
+            if (!currentNodeKeys.has(key)) {
                 this.container.removeChild(container);
                 container.destroy(true);
-                this.nodes.delete(nodeName);
+                this.nodes.delete(key);
             }
         });
 
@@ -344,7 +350,7 @@ export class Visualizer {
         nodesList.forEach(node => {
             const nodePods = node.name === TEXTS.pending_zone.name
                 ? pendingPods
-                : filteredPods.filter(p => p.node_name === node.name);
+                : filteredPods.filter(p => p.node_name === node.name && p.cluster === node.cluster);
             const podCount = nodePods.length;
 
             let podsPerRow = Math.ceil(Math.sqrt(podCount));
@@ -373,7 +379,7 @@ export class Visualizer {
             if (width > maxNodeWidth) maxNodeWidth = width;
             if (height > maxNodeHeight) maxNodeHeight = height;
 
-            nodeLayouts.set(node.name, {width, height, podsPerRow});
+            nodeLayouts.set(nodeKey(node.cluster, node.name), {width, height, podsPerRow});
         });
 
 
@@ -407,9 +413,7 @@ export class Visualizer {
         const defaultZoneName = 'No Zone';
 
         nodesList.forEach(node => {
-            const zone = node.labels['failure-domain.beta.kubernetes.io/zone'] ||
-                node.labels['topology.kubernetes.io/zone'] ||
-                defaultZoneName;
+            const zone = node.zone || defaultZoneName;
 
             if (!nodesByZone.has(zone)) {
                 nodesByZone.set(zone, []);
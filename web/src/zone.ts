@@ -2,12 +2,13 @@ import {Node, NodeContainer, NodeLayout, Pod} from "./types";
 import {COLORS, LAYOUT, TEXTS} from "./constants";
 import {Container, Graphics, Text, TextStyle } from 'pixi.js';
 import {createNodeContainer, renderNode} from "./node";
+import {nodeKey} from "./utils";
 
 export function drawZone(nodeContainers: Map<string, NodeContainer>, drawContainer: Container, nodesByZone: Map<string, Node[]>, zoneName: string, newZoneLabelsContainer : Container, currentY: number , nodeLayouts: Map<string, NodeLayout>, layoutWidth : number, pendingPods: Pod[], filteredPods: Pod[], zooomAnimation : boolean, sortOrder: string) : number  {
     const nodesInZone = nodesByZone.get(zoneName)!;
 
     // Sort nodes within zone by name
-    nodesInZone.sort((a, b) => a.name.localeCompare(b.name));
+    nodesInZone.sort((a, b) => a.name.localeCompare(b.name) || a.cluster.localeCompare(b.cluster));
 
     // Draw Zone Header
     const zoneTitleStyle = new TextStyle({
@@ -36,7 +37,7 @@ export function drawZone(nodeContainers: Map<string, NodeContainer>, drawContain
     let currentRowHeight = 0;
 
     nodesInZone.forEach(node => {
-        const layout = nodeLayouts.get(node.name)!;
+        const layout = nodeLayouts.get(nodeKey(node.cluster, node.name))!;
 
         if (currentX + layout.width > layoutWidth + LAYOUT.padding && currentX > LAYOUT.padding) {
             currentX = LAYOUT.padding;
@@ -46,7 +47,7 @@ export function drawZone(nodeContainers: Map<string, NodeContainer>, drawContain
 
         const nodePods = node.name === TEXTS.pending_zone.name
             ? pendingPods
-            : filteredPods.filter(p => p.node_name === node.name);
+            : filteredPods.filter(p => p.node_name === node.name && p.cluster === node.cluster);
         creatreAndRenderNode(nodeContainers, drawContainer, node, nodePods, currentX, currentY, layout.width, layout.height, layout.podsPerRow, zooomAnimation, sortOrder);
 
         if (layout.height > currentRowHeight) currentRowHeight = layout.height + 15;
@@ -56,11 +57,12 @@ export function drawZone(nodeContainers: Map<string, NodeContainer>, drawContain
 }
 
 export function creatreAndRenderNode(nodeContainers: Map<string, NodeContainer>, drawContainer: Container, node: Node, pods: Pod[], x: number, y: number, width: number, height: number, podsPerRow: number, zooomAnimation: boolean, sortOrder: string) {
-    let nodeContainer = nodeContainers.get(node.name);
+    const key = nodeKey(node.cluster, node.name);
+    let nodeContainer = nodeContainers.get(key);
     if (!nodeContainer) {
         nodeContainer = createNodeContainer(node);
         drawContainer.addChild(nodeContainer);
-        nodeContainers.set(node.name, nodeContainer);
+        nodeContainers.set(key, nodeContainer);
     }
     nodeContainer.x = x;
     nodeContainer.y = y;